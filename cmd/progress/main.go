@@ -0,0 +1,149 @@
+// Command progress renders progress messages read as JSON Lines from stdin or from a file, e.g. a named pipe. Each line must be a JSON encoded messages.Update, for example:
+//
+//	{"key": "build", "message": "Building the project", "status": "started"}
+//	{"key": "build", "status": "success"}
+//
+// To run commands as progress messages, use the run sub-command, for example:
+//
+//	progress run -key build -message "Building the project" -- make all
+//
+// While the command is running, its latest output line is outputted as progress message. If the command fails, the last lines of its output are outputted as details of the message. Multiple commands can be run in parallel by listing them in a JSON manifest file given with -manifest flag, for example:
+//
+//	[
+//	  {"key": "build", "message": "Building the project", "command": ["make", "all"]},
+//	  {"key": "lint", "message": "Linting the project", "command": ["golangci-lint", "run"]}
+//	]
+//
+// The command exits with 0, if all messages succeeded, 1, if any of the messages failed, 2, if any of the messages finished with warning status, and 3, if the input or the arguments were invalid.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+const exitCodeInvalidInput = 3
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	exitCode := run(ctx, os.Args[1:], os.Stdin, os.Stderr)
+	stop()
+	os.Exit(exitCode)
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "run" {
+		return runCommands(ctx, args[1:], stderr)
+	}
+
+	flags := flag.NewFlagSet("progress", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	input := flags.String("input", "", "read updates from given file, e.g. a named pipe, instead of stdin")
+	summary := flags.Bool("summary", false, "render summary of the messages after the input has been read")
+	outputFormat := flags.String("output", string(messages.OutputFormatHuman), `output format: "human" or "json"`)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return messages.ExitCodeSuccess
+		}
+		return exitCodeInvalidInput
+	}
+
+	format := messages.OutputFormat(*outputFormat)
+	if format != messages.OutputFormatHuman && format != messages.OutputFormatJSON {
+		fmt.Fprintf(stderr, "progress: invalid output format %q\n", *outputFormat)
+		return exitCodeInvalidInput
+	}
+
+	r := stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintf(stderr, "progress: %v\n", err)
+			return exitCodeInvalidInput
+		}
+		defer file.Close()
+		r = file
+	}
+
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = stderr
+	cfg.ShowSummary = *summary
+	cfg.OutputFormat = format
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.StartContext(ctx)
+	inputErr := pushUpdates(ctx, taskLog, r)
+	_ = taskLog.Finish()
+
+	if inputErr {
+		return exitCodeInvalidInput
+	}
+	return taskLog.Summary().ExitCode()
+}
+
+// pushUpdates pushes updates read from r into the progress log until the input ends or ctx is cancelled. Invalid lines are reported in the progress log output. Returns true, if reading the input failed or the input contained invalid updates.
+func pushUpdates(ctx context.Context, taskLog *progress.Progress, r io.Reader) bool {
+	output := taskLog.Writer()
+	inputErr := false
+
+	// Input is read in a separate goroutine as reading, e.g., an idle named pipe blocks until the next line is written and would prevent reacting to cancellation.
+	lines := make(chan []byte)
+	var readErr error
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- append([]byte(nil), scanner.Bytes()...):
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr = scanner.Err()
+	}()
+
+	for line := 1; ; line++ {
+		var data []byte
+		var ok bool
+		select {
+		case <-ctx.Done():
+			// The progress log has already rendered the cancelled messages.
+			return false
+		case data, ok = <-lines:
+		}
+		if !ok {
+			break
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		var update messages.Update
+		err := json.Unmarshal(data, &update)
+		if err == nil {
+			err = taskLog.Push(update)
+		}
+		if err != nil {
+			inputErr = true
+			fmt.Fprintf(output, "progress: invalid update on line %d: %v\n", line, err)
+		}
+	}
+	if readErr != nil {
+		inputErr = true
+		fmt.Fprintf(output, "progress: failed to read input: %v\n", readErr)
+	}
+	return inputErr
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UpCloudLtd/progress/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name     string
+		input    string
+		exitCode int
+		output   string
+	}{
+		{
+			name:     "Success",
+			input:    `{"key": "build", "message": "Test build", "status": "started"}` + "\n\n" + `{"key": "build", "status": "success"}`,
+			exitCode: messages.ExitCodeSuccess,
+			output:   "Test build",
+		},
+		{
+			name:     "Warning",
+			input:    `{"message": "Test warning", "status": "warning", "details": "test details"}`,
+			exitCode: messages.ExitCodeWarning,
+			output:   "test details",
+		},
+		{
+			name:     "Error",
+			input:    `{"key": "build", "message": "Test build", "status": "started"}` + "\n" + `{"key": "build", "status": "error", "details": "test error"}`,
+			exitCode: messages.ExitCodeError,
+			output:   "test error",
+		},
+		{
+			name:     "Input ends before message finishes",
+			input:    `{"key": "deploy", "message": "Test deploy", "status": "started"}`,
+			exitCode: messages.ExitCodeError,
+			output:   "Test deploy",
+		},
+		{
+			name:     "Invalid update",
+			input:    `{"key": "build", "status": "started"}` + "\n" + `not json`,
+			exitCode: exitCodeInvalidInput,
+			output:   "progress: invalid update on line 1: can not push message with empty message\nprogress: invalid update on line 2: invalid character",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			stderr := bytes.NewBuffer(nil)
+			exitCode := run(context.Background(), []string{"-output", "human"}, strings.NewReader(test.input), stderr)
+			assert.Equal(t, test.exitCode, exitCode)
+			assert.Contains(t, stderr.String(), test.output)
+		})
+	}
+}
+
+func TestRun_Input(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "updates.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"message": "Test success", "status": "success"}`+"\n"), 0o600))
+
+	stderr := bytes.NewBuffer(nil)
+	exitCode := run(context.Background(), []string{"-input", path, "-output", "json", "-summary"}, strings.NewReader(""), stderr)
+	assert.Equal(t, messages.ExitCodeSuccess, exitCode)
+	assert.Contains(t, stderr.String(), `"message":"Test success","status":"success"`)
+	assert.Contains(t, stderr.String(), `{"summary":{"status_counts":{"success":1}`)
+
+	exitCode = run(context.Background(), []string{"-input", filepath.Join(t.TempDir(), "missing.jsonl")}, strings.NewReader(""), stderr)
+	assert.Equal(t, exitCodeInvalidInput, exitCode)
+
+	exitCode = run(context.Background(), []string{"-output", "yaml"}, strings.NewReader(""), stderr)
+	assert.Equal(t, exitCodeInvalidInput, exitCode)
+	assert.Contains(t, stderr.String(), `progress: invalid output format "yaml"`)
+}
+
+func TestRun_CancelWhileInputIsIdle(t *testing.T) {
+	t.Parallel()
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stderr := bytes.NewBuffer(nil)
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, nil, r, stderr)
+	}()
+
+	// Each write blocks until the previous line has been received from the reading goroutine, so the update has been pushed once the last write returns.
+	for _, line := range []string{`{"key": "build", "message": "Test build", "status": "started"}`, "", ""} {
+		_, err := w.Write([]byte(line + "\n"))
+		assert.NoError(t, err)
+	}
+	cancel()
+
+	select {
+	case exitCode := <-done:
+		assert.Equal(t, messages.ExitCodeError, exitCode)
+		assert.Contains(t, stderr.String(), "context canceled")
+	case <-time.After(time.Second * 5):
+		t.Fatal("run did not return after the context was cancelled")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// outputTailLines defines how many of the last lines of output of a failed command are included in the details of its message.
+const outputTailLines = 20
+
+// manifestCommand is a command in a manifest file given to the run sub-command.
+type manifestCommand struct {
+	Key     string   `json:"key"`
+	Message string   `json:"message"`
+	Command []string `json:"command"`
+}
+
+func readManifest(path string) ([]manifestCommand, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Error already contains the path.
+	}
+
+	var commands []manifestCommand
+	if err := json.Unmarshal(data, &commands); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	for i, command := range commands {
+		if len(command.Command) == 0 {
+			return nil, fmt.Errorf("command %d in manifest %s is empty", i+1, path)
+		}
+	}
+	return commands, nil
+}
+
+func runCommands(ctx context.Context, args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("progress run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: progress run [flags] -- command [args...]\n       progress run [flags] -manifest commands.json")
+		flags.PrintDefaults()
+	}
+	key := flags.String("key", "", "key of the message, defaults to message")
+	message := flags.String("message", "", "message to output for the command, defaults to the command")
+	manifest := flags.String("manifest", "", "run commands listed in given JSON file in parallel")
+	limit := flags.Int("limit", -1, "maximum number of commands to run in parallel, negative value removes the limit")
+	failFast := flags.Bool("fail-fast", false, "cancel other commands when a command fails")
+	summary := flags.Bool("summary", false, "render summary of the messages after the commands have finished")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return messages.ExitCodeSuccess
+		}
+		return exitCodeInvalidInput
+	}
+
+	commands, ok := listCommands(flags, *key, *message, *manifest, stderr)
+	if !ok {
+		return exitCodeInvalidInput
+	}
+
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = stderr
+	cfg.ShowSummary = *summary
+	cfg.CmdOutputTailLines = outputTailLines
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.StartContext(ctx)
+
+	group := progress.NewGroup(taskLog)
+	group.SetLimit(*limit)
+	group.SetFailFast(*failFast)
+	for _, command := range commands {
+		cmd := exec.Command(command.Command[0], command.Command[1:]...) // #nosec G204 -- Running user given commands is the purpose of the run sub-command.
+		group.GoCmd(command.Key, command.Message, cmd)
+	}
+	_ = group.Wait()
+	_ = taskLog.Finish()
+
+	return taskLog.Summary().ExitCode()
+}
+
+// listCommands lists the commands to run from the manifest or the arguments of the run sub-command. Messages of the commands default to the command. Returns false, if the arguments are invalid.
+func listCommands(flags *flag.FlagSet, key, message, manifest string, stderr io.Writer) ([]manifestCommand, bool) {
+	var commands []manifestCommand
+	switch {
+	case manifest != "" && flags.NArg() > 0:
+		fmt.Fprintln(stderr, "progress: can not run both manifest and command")
+		return nil, false
+	case manifest != "":
+		var err error
+		if commands, err = readManifest(manifest); err != nil {
+			fmt.Fprintf(stderr, "progress: %v\n", err)
+			return nil, false
+		}
+	case flags.NArg() > 0:
+		commands = []manifestCommand{{Key: key, Message: message, Command: flags.Args()}}
+	default:
+		flags.Usage()
+		return nil, false
+	}
+
+	for i := range commands {
+		if commands[i].Message == "" {
+			commands[i].Message = strings.Join(commands[i].Command, " ")
+		}
+	}
+	return commands, true
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/UpCloudLtd/progress/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHelperProcess is executed as the command run in the tests. It prints the arguments after the exit code, one per line, and exits with the exit code.
+func TestHelperProcess(t *testing.T) {
+	t.Parallel()
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		// Not executed as a helper process.
+		return
+	}
+
+	for _, line := range args[2:] {
+		fmt.Fprintln(os.Stdout, line)
+	}
+	exitCode, _ := strconv.Atoi(args[1])
+	os.Exit(exitCode)
+}
+
+func helperCommand(exitCode int, lines ...string) []string {
+	return append([]string{os.Args[0], "-test.run=^TestHelperProcess$", "--", strconv.Itoa(exitCode)}, lines...)
+}
+
+func TestRunCommands(t *testing.T) {
+	t.Parallel()
+	stderr := bytes.NewBuffer(nil)
+	args := append([]string{"run", "-key", "build", "-message", "Test build", "--"}, helperCommand(0, "building")...)
+	exitCode := run(context.Background(), args, strings.NewReader(""), stderr)
+	assert.Equal(t, messages.ExitCodeSuccess, exitCode)
+	assert.Contains(t, stderr.String(), "Test build")
+	assert.NotContains(t, stderr.String(), "building")
+
+	stderr.Reset()
+	args = append([]string{"run", "--"}, helperCommand(2, "first", "last")...)
+	exitCode = run(context.Background(), args, strings.NewReader(""), stderr)
+	assert.Equal(t, messages.ExitCodeError, exitCode)
+	assert.Contains(t, stderr.String(), "first")
+	assert.Contains(t, stderr.String(), "last")
+	assert.Contains(t, stderr.String(), "exit status 2")
+}
+
+func TestRunCommands_OutputTail(t *testing.T) {
+	t.Parallel()
+	lines := make([]string, 0, outputTailLines+5)
+	for i := 1; i <= outputTailLines+5; i++ {
+		lines = append(lines, fmt.Sprintf("line %02d", i))
+	}
+
+	stderr := bytes.NewBuffer(nil)
+	args := append([]string{"run", "-message", "Test tail", "--"}, helperCommand(1, lines...)...)
+	exitCode := run(context.Background(), args, strings.NewReader(""), stderr)
+	assert.Equal(t, messages.ExitCodeError, exitCode)
+	for _, line := range lines[:5] {
+		assert.NotContains(t, stderr.String(), line)
+	}
+	for _, line := range lines[5:] {
+		assert.Contains(t, stderr.String(), line)
+	}
+}
+
+func TestRunCommands_Manifest(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	manifest := fmt.Sprintf(`[{"key": "success", "message": "Test success", "command": %s}, {"key": "error", "message": "Test error", "command": %s}]`,
+		jsonArray(helperCommand(0)), jsonArray(helperCommand(1, "test error")))
+	assert.NoError(t, os.WriteFile(path, []byte(manifest), 0o600))
+
+	stderr := bytes.NewBuffer(nil)
+	exitCode := run(context.Background(), []string{"run", "-manifest", path, "-limit", "1", "-summary"}, strings.NewReader(""), stderr)
+	assert.Equal(t, messages.ExitCodeError, exitCode)
+	assert.Contains(t, stderr.String(), "Test success")
+	assert.Contains(t, stderr.String(), "test error")
+	assert.Contains(t, stderr.String(), "1 success")
+
+	exitCode = run(context.Background(), []string{"run", "-manifest", path, "--", "echo"}, strings.NewReader(""), stderr)
+	assert.Equal(t, exitCodeInvalidInput, exitCode)
+
+	exitCode = run(context.Background(), []string{"run"}, strings.NewReader(""), stderr)
+	assert.Equal(t, exitCodeInvalidInput, exitCode)
+}
+
+func jsonArray(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
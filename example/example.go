@@ -36,11 +36,22 @@ func main() {
 		Status:  messages.MessageStatusStarted,
 	})
 
+	_ = taskLog.Push(messages.Update{
+		Key:     "numeric-progress-example",
+		Message: "Numeric progress is rendered as a progress bar",
+		Status:  messages.MessageStatusStarted,
+		Total:   10,
+	})
+
 	time.Sleep(time.Millisecond * 300)
 	for i := 1; i < 10; i++ {
 		_ = taskLog.Push(messages.Update{
 			Key:             "progress-example",
-			ProgressMessage: fmt.Sprintf("(%d%%)", i*10),
+			ProgressMessage: fmt.Sprintf("(step %d/9)", i),
+		})
+		_ = taskLog.Push(messages.Update{
+			Key:     "numeric-progress-example",
+			Current: int64(i),
 		})
 		time.Sleep(time.Millisecond * 300)
 	}
@@ -50,6 +61,11 @@ func main() {
 		Status: messages.MessageStatusSuccess,
 	})
 
+	_ = taskLog.Push(messages.Update{
+		Key:    "numeric-progress-example",
+		Status: messages.MessageStatusSuccess,
+	})
+
 	_ = taskLog.Push(messages.Update{
 		Key:     "first-example",
 		Message: "Progress messages can be updated while they are in pending or started state",
@@ -0,0 +1,196 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// cmdProgressInterval limits how often the latest output line of a command is pushed as progress message.
+const cmdProgressInterval = time.Millisecond * 100
+
+// cmdOutput collects the output of a command and pushes its latest line as progress message of the task. If tailLines is positive, only the last tailLines lines of the output are kept.
+type cmdOutput struct {
+	mu        sync.Mutex
+	task      *Task
+	tailLines int
+	lines     []string
+	line      []byte
+	carriage  bool
+	pushed    time.Time
+	// latest is the latest line that has not been pushed yet because of cmdProgressInterval. It is pushed by timer.
+	latest  string
+	timer   *time.Timer
+	stopped bool
+}
+
+func (o *cmdOutput) Write(b []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, c := range b {
+		switch c {
+		case '\n':
+			o.appendLine()
+		case '\r':
+			// Tools often use carriage return to update progress on a single line: the line is pushed as progress message and overwritten by the next one, unless followed by line break.
+			o.pushProgress()
+			o.carriage = true
+		default:
+			if o.carriage {
+				o.line = o.line[:0]
+				o.carriage = false
+			}
+			o.line = append(o.line, c)
+		}
+	}
+	return len(b), nil
+}
+
+func (o *cmdOutput) pushProgress() {
+	line := strings.TrimSpace(string(o.line))
+	if line == "" || o.stopped {
+		return
+	}
+
+	o.latest = line
+	if wait := cmdProgressInterval - time.Since(o.pushed); wait > 0 {
+		if o.timer == nil {
+			o.timer = time.AfterFunc(wait, o.flush)
+		}
+		return
+	}
+	o.pushLatest()
+}
+
+// flush pushes the latest line that was held back by cmdProgressInterval.
+func (o *cmdOutput) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.timer = nil
+	if !o.stopped {
+		o.pushLatest()
+	}
+}
+
+func (o *cmdOutput) pushLatest() {
+	if o.latest == "" {
+		return
+	}
+	o.pushed = time.Now()
+	_ = o.task.SetProgress(o.latest)
+	o.latest = ""
+}
+
+// stop stops pushing progress messages. Called when the command has exited.
+func (o *cmdOutput) stop() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	o.stopped = true
+}
+
+func (o *cmdOutput) appendLine() {
+	o.pushProgress()
+	o.lines = append(o.lines, string(o.line))
+	if o.tailLines > 0 && len(o.lines) > o.tailLines {
+		o.lines = o.lines[1:]
+	}
+	o.line = o.line[:0]
+	o.carriage = false
+}
+
+// details returns the collected output of the command followed by err.
+func (o *cmdOutput) details(err error) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.line) > 0 {
+		o.appendLine()
+	}
+	output := strings.TrimSpace(strings.Join(o.lines, "\n"))
+	if output == "" {
+		return err.Error()
+	}
+	return output + "\n" + err.Error()
+}
+
+func teeOutput(original io.Writer, output *cmdOutput) io.Writer {
+	if original == nil {
+		return output
+	}
+	return io.MultiWriter(original, output)
+}
+
+// RunCmd pushes a started message to the progress log and runs cmd. While the command is running, its latest output line is outputted as progress message in TTY terminals. The message is finished with success status, if the command exits with zero exit code, and with error status, and the output of the command as details, otherwise. The number of output lines included in the details can be limited with CmdOutputTailLines output configuration option. If cmd has Stdout or Stderr set, the output is also written to them.
+//
+// The command is killed, if the context given to StartContext is cancelled or the message times out. Returns the error returned by cmd.Wait, e.g. *exec.ExitError, as is. If the message times out, the returned error wraps both ErrTimedOut and the error returned by cmd.Wait, if any.
+func RunCmd(p *Progress, key, message string, cmd *exec.Cmd) error {
+	return p.Task(key, message).RunCmd(cmd)
+}
+
+// RunCmd starts the task, runs cmd, and finishes the task based on the result. See RunCmd function for details.
+func (t *Task) RunCmd(cmd *exec.Cmd) error {
+	if err := t.Start(); err != nil {
+		return err
+	}
+
+	return t.runCmd(t.progress.context(), cmd)
+}
+
+func (t *Task) runCmd(ctx context.Context, cmd *exec.Cmd) error {
+	output := &cmdOutput{task: t, tailLines: t.progress.config.CmdOutputTailLines}
+	cmd.Stdout = teeOutput(cmd.Stdout, output)
+	cmd.Stderr = teeOutput(cmd.Stderr, output)
+
+	if err := cmd.Start(); err != nil {
+		_ = t.Fail(err)
+		return err //nolint:wrapcheck // Errors of the command are returned as is.
+	}
+
+	// Kill the command, if the context is cancelled or the task times out.
+	timedOut := t.TimedOut()
+	done := make(chan bool)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-timedOut:
+		case <-done:
+			return
+		}
+		_ = cmd.Process.Kill()
+	}()
+
+	err := cmd.Wait()
+	close(done)
+	output.stop()
+
+	// If the task timed out, its message has already been finished.
+	if isClosed(timedOut) {
+		if err == nil {
+			return ErrTimedOut
+		}
+		return fmt.Errorf("%w: %w", ErrTimedOut, err)
+	}
+
+	if err != nil {
+		_ = t.push(messages.Update{
+			Status:  messages.MessageStatusError,
+			Details: output.details(err),
+			Err:     err,
+		})
+		return err //nolint:wrapcheck // Errors of the command are returned as is.
+	}
+	return t.Succeed()
+}
@@ -0,0 +1,171 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHelperProcess is executed as the command run in the tests. It prints the arguments after the exit code, one per line, and exits with the exit code. Exit code "sleep" makes the process sleep instead.
+func TestHelperProcess(t *testing.T) {
+	t.Parallel()
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		// Not executed as a helper process.
+		return
+	}
+
+	for _, line := range args[2:] {
+		fmt.Fprintln(os.Stdout, line)
+	}
+	if args[1] == "sleep" {
+		time.Sleep(time.Minute)
+	}
+	exitCode, _ := strconv.Atoi(args[1])
+	os.Exit(exitCode)
+}
+
+func helperCommand(exitCode string, lines ...string) *exec.Cmd {
+	args := append([]string{"-test.run=^TestHelperProcess$", "--", exitCode}, lines...)
+	return exec.Command(os.Args[0], args...)
+}
+
+func TestRunCmd(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	stdout := bytes.NewBuffer(nil)
+	cmd := helperCommand("0", "test output")
+	cmd.Stdout = stdout
+	err := progress.RunCmd(taskLog, "success", "Test success", cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, "test output\n", stdout.String())
+
+	err = progress.RunCmd(taskLog, "error", "Test error", helperCommand("2", "first", "last"))
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode())
+
+	assert.ErrorIs(t, taskLog.Finish(), err)
+
+	// Slow helper process could get the messages rendered also in started state and with stopwatch, so only check the finished messages.
+	assert.Regexp(t, `(?m)^✓ Test success`, buf.String())
+	assert.Regexp(t, `(?m)^✗ Test error.*\n  first\n  last\n  exit status 2\n$`, buf.String())
+}
+
+func TestRunCmd_Cancel(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	taskLog := progress.NewProgress(cfg)
+	taskLog.StartContext(ctx)
+	defer taskLog.Stop()
+
+	time.AfterFunc(time.Millisecond*100, cancel)
+	err := progress.RunCmd(taskLog, "cancel", "Test cancel", helperCommand("sleep"))
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr), "error should be *exec.ExitError, got %v", err)
+}
+
+func TestRunCmd_ErrorsIfCommandNotFound(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+	defer taskLog.Stop()
+
+	err := progress.RunCmd(taskLog, "not-found", "Test not found", exec.Command("progress-test-command-not-found"))
+	assert.ErrorIs(t, err, exec.ErrNotFound)
+}
+
+func TestRunCmd_OutputTail(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+	cfg.CmdOutputTailLines = 2
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	err := progress.RunCmd(taskLog, "error", "Test error", helperCommand("1", "first", "progress 1\rprogress 2", "last"))
+	assert.Error(t, err)
+	taskLog.Stop()
+
+	assert.Contains(t, buf.String(), "\n  progress 2\n  last\n  exit status 1\n")
+	assert.NotContains(t, buf.String(), "first")
+	assert.NotContains(t, buf.String(), "progress 1")
+}
+
+func TestRunCmd_PushesLatestLine(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	taskLog := progress.NewProgress(cfg)
+	taskLog.StartContext(ctx)
+	defer taskLog.Stop()
+
+	events, unsubscribe := taskLog.Subscribe()
+	defer unsubscribe()
+
+	done := make(chan error)
+	go func() {
+		done <- progress.RunCmd(taskLog, "latest", "Test latest", helperCommand("sleep", "first", "second", "third"))
+	}()
+
+	// Lines printed in quick succession are pushed at most once per 100 ms, but the latest line must be pushed eventually, even if the command does not output anything after it.
+	timeout := time.After(time.Second * 10)
+	latest := ""
+	for latest != "third" {
+		select {
+		case event := <-events:
+			latest = event.Message.ProgressMessage
+		case <-timeout:
+			t.Fatalf("latest line was not pushed as progress message, got %q", latest)
+		}
+	}
+	cancel()
+	assert.Error(t, <-done)
+}
+
+func TestRunCmd_Timeout(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+	defer taskLog.Stop()
+
+	task := taskLog.Task("timeout", "Test timeout")
+	assert.NoError(t, task.SetTimeout(time.Millisecond*50))
+	err := task.RunCmd(helperCommand("sleep"))
+	assert.ErrorIs(t, err, progress.ErrTimedOut)
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+}
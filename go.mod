@@ -1,11 +1,12 @@
 module github.com/UpCloudLtd/progress
 
-go 1.20
+go 1.21
 
 require (
 	github.com/bradleyjkemp/cupaloy/v2 v2.8.0
 	github.com/jedib0t/go-pretty/v6 v6.4.9
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/sys v0.14.0
 	golang.org/x/term v0.14.0
 )
 
@@ -14,6 +15,5 @@ require (
 	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
-	golang.org/x/sys v0.14.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
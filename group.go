@@ -0,0 +1,136 @@
+package progress
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// Group executes functions as progress messages in parallel goroutines. Each message is pending until its function is executed, started while the function is being executed, and finished based on the error returned by the function.
+type Group struct {
+	progress *Progress
+	ctx      context.Context //nolint:containedctx // Context is shared by all functions executed in the group.
+	cancel   context.CancelCauseFunc
+	failFast bool
+	sem      chan bool
+	wg       sync.WaitGroup
+	errOnce  sync.Once
+	err      error
+}
+
+// NewGroup creates new Group that pushes its messages to given Progress. The context passed to the executed functions is derived from the context given to StartContext.
+func NewGroup(p *Progress) *Group {
+	ctx, cancel := context.WithCancelCause(p.context())
+	return &Group{
+		progress: p,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// SetLimit limits the number of functions executed in parallel to n. Negative value removes the limit. Panics if called while functions are being executed.
+func (g *Group) SetLimit(n int) {
+	if len(g.sem) != 0 {
+		panic("can not modify limit while functions are being executed")
+	}
+
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan bool, n)
+}
+
+// SetFailFast configures the group to cancel the context passed to the executed functions when the first function returns an error. Messages of functions that have not been started yet are set to skipped.
+func (g *Group) SetFailFast(failFast bool) {
+	g.failFast = failFast
+}
+
+func (g *Group) setError(err error) {
+	g.errOnce.Do(func() {
+		g.err = err
+		if g.failFast {
+			g.cancel(err)
+		}
+	})
+}
+
+func (g *Group) skip(task *Task) {
+	_ = task.push(messages.Update{
+		Status:  messages.MessageStatusSkipped,
+		Details: context.Cause(g.ctx).Error(),
+	})
+}
+
+func (g *Group) acquire() bool {
+	if g.sem == nil {
+		return g.ctx.Err() == nil
+	}
+
+	select {
+	case g.sem <- true:
+		if g.ctx.Err() != nil {
+			<-g.sem
+			return false
+		}
+		return true
+	case <-g.ctx.Done():
+		return false
+	}
+}
+
+func (g *Group) release() {
+	if g.sem != nil {
+		<-g.sem
+	}
+}
+
+// Go pushes a pending message to the progress log and executes fn in a new goroutine once the limit set with SetLimit allows it. The message is started when fn is executed and finished based on the error returned by fn, as with Run.
+func (g *Group) Go(key, message string, fn func(ctx context.Context) error) {
+	g.goTask(key, message, func(task *Task) error {
+		return task.run(g.ctx, fn)
+	})
+}
+
+// GoCmd pushes a pending message to the progress log and runs cmd in a new goroutine once the limit set with SetLimit allows it. The message is started when cmd is run and finished based on its result, as with RunCmd. The command is killed, if the context of the group is cancelled.
+func (g *Group) GoCmd(key, message string, cmd *exec.Cmd) {
+	g.goTask(key, message, func(task *Task) error {
+		return task.runCmd(g.ctx, cmd)
+	})
+}
+
+func (g *Group) goTask(key, message string, run func(task *Task) error) {
+	task := g.progress.Task(key, message)
+	if err := task.Pending(); err != nil {
+		g.setError(err)
+		return
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if !g.acquire() {
+			g.skip(task)
+			return
+		}
+		defer g.release()
+
+		if err := task.Start(); err != nil {
+			g.setError(err)
+			return
+		}
+		if err := run(task); err != nil {
+			g.setError(err)
+		}
+	}()
+}
+
+// Wait blocks until all functions executed with Go have returned and returns the first error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err
+}
@@ -0,0 +1,95 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_SetLimit(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	var running, maxRunning int32
+	group := progress.NewGroup(taskLog)
+	group.SetLimit(2)
+	for i := 0; i < 6; i++ {
+		group.Go("", fmt.Sprintf("Test task %d", i), func(_ context.Context) error {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				prev := atomic.LoadInt32(&maxRunning)
+				if current <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 10)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	assert.NoError(t, group.Wait())
+	taskLog.Stop()
+
+	assert.Equal(t, int32(2), maxRunning)
+	assert.Equal(t, 6, strings.Count(buf.String(), "✓ Test task"))
+}
+
+func TestGroup_FailFast(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	testErr := errors.New("test error")
+	group := progress.NewGroup(taskLog)
+	group.SetLimit(1)
+	group.SetFailFast(true)
+	for i := 0; i < 3; i++ {
+		group.Go("", fmt.Sprintf("Test task %d", i), func(_ context.Context) error {
+			return testErr
+		})
+	}
+	assert.ErrorIs(t, group.Wait(), testErr)
+	taskLog.Stop()
+
+	output := buf.String()
+	assert.Equal(t, 1, strings.Count(output, "✗ Test task"))
+	assert.Equal(t, 2, strings.Count(output, "- Test task"))
+	// Skipped messages have the error that cancelled the group as details.
+	assert.Equal(t, 3, strings.Count(output, "\n  test error\n"))
+}
+
+func TestGroup_Wait_ReturnsFirstError(t *testing.T) {
+	t.Parallel()
+	taskLog := progress.NewProgress(nil)
+	taskLog.Start()
+	defer taskLog.Stop()
+
+	testErr := errors.New("test error")
+	group := progress.NewGroup(taskLog)
+	group.Go("success", "Test success", func(_ context.Context) error {
+		return nil
+	})
+	group.Go("error", "Test error", func(_ context.Context) error {
+		return testErr
+	})
+	assert.ErrorIs(t, group.Wait(), testErr)
+}
@@ -0,0 +1,136 @@
+package progress
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// byteProgressInterval limits how often byte progress is pushed to the progress log to avoid pushing an update for every read or write.
+const byteProgressInterval = time.Millisecond * 100
+
+type byteProgress struct {
+	task     *Task
+	current  int64
+	pushed   time.Time
+	finished bool
+	err      error
+}
+
+func newByteProgress(p *Progress, key, message string, totalBytes int64) *byteProgress {
+	bp := &byteProgress{task: p.Task(key, message)}
+	bp.err = bp.task.push(messages.Update{
+		Status: messages.MessageStatusStarted,
+		Total:  totalBytes,
+		Unit:   messages.ProgressUnitBytes,
+	})
+	return bp
+}
+
+func (bp *byteProgress) add(n int) {
+	bp.current += int64(n)
+	if bp.finished || bp.err != nil || time.Since(bp.pushed) < byteProgressInterval {
+		return
+	}
+
+	bp.pushed = time.Now()
+	_ = bp.task.push(messages.Update{Current: bp.current})
+}
+
+func (bp *byteProgress) finish(err error) {
+	if bp.finished || bp.err != nil {
+		return
+	}
+	bp.finished = true
+
+	update := messages.Update{
+		Current: bp.current,
+		Status:  messages.MessageStatusSuccess,
+	}
+	if err != nil {
+		update.Status = messages.MessageStatusError
+		update.Details = err.Error()
+		update.Err = err
+	}
+	_ = bp.task.push(update)
+}
+
+// Reader is an io.Reader that reports the number of bytes read as numeric progress of a progress message.
+type Reader struct {
+	progress *byteProgress
+	reader   io.Reader
+}
+
+// NewReader pushes a started message to the progress log and returns a Reader that updates the message as data is read from r. The message is finished with success status when r returns io.EOF or the Reader is closed, and with error status when r returns any other error. Use zero totalBytes, if the size of the data is not known.
+func NewReader(p *Progress, key, message string, r io.Reader, totalBytes int64) *Reader {
+	return &Reader{
+		progress: newByteProgress(p, key, message, totalBytes),
+		reader:   r,
+	}
+}
+
+// Read reads data from the underlying reader. Returns error, if starting the progress message failed.
+func (r *Reader) Read(b []byte) (int, error) {
+	if err := r.progress.err; err != nil {
+		return 0, err
+	}
+
+	n, err := r.reader.Read(b)
+	r.progress.add(n)
+	if errors.Is(err, io.EOF) {
+		r.progress.finish(nil)
+	} else if err != nil {
+		r.progress.finish(err)
+	}
+	return n, err //nolint:wrapcheck // Errors are returned as is, as, for example, io.EOF must not be wrapped.
+}
+
+// Close finishes the progress message and closes the underlying reader, if it implements io.Closer.
+func (r *Reader) Close() error {
+	var err error
+	if closer, ok := r.reader.(io.Closer); ok {
+		err = closer.Close()
+	}
+	r.progress.finish(err)
+	return err //nolint:wrapcheck // Errors of the underlying reader are returned as is.
+}
+
+// Writer is an io.Writer that reports the number of bytes written as numeric progress of a progress message.
+type Writer struct {
+	progress *byteProgress
+	writer   io.Writer
+}
+
+// NewWriter pushes a started message to the progress log and returns a Writer that updates the message as data is written to w. The message is finished with success status when the Writer is closed, and with error status when w returns an error. Use zero totalBytes, if the size of the data is not known.
+func NewWriter(p *Progress, key, message string, w io.Writer, totalBytes int64) *Writer {
+	return &Writer{
+		progress: newByteProgress(p, key, message, totalBytes),
+		writer:   w,
+	}
+}
+
+// Write writes data to the underlying writer. Returns error, if starting the progress message failed.
+func (w *Writer) Write(b []byte) (int, error) {
+	if err := w.progress.err; err != nil {
+		return 0, err
+	}
+
+	n, err := w.writer.Write(b)
+	w.progress.add(n)
+	if err != nil {
+		w.progress.finish(err)
+	}
+	return n, err //nolint:wrapcheck // Errors of the underlying writer are returned as is.
+}
+
+// Close finishes the progress message and closes the underlying writer, if it implements io.Closer.
+func (w *Writer) Close() error {
+	var err error
+	if closer, ok := w.writer.(io.Closer); ok {
+		err = closer.Close()
+	}
+	w.progress.finish(err)
+	return err //nolint:wrapcheck // Errors of the underlying writer are returned as is.
+}
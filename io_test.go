@@ -0,0 +1,76 @@
+package progress_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(_ []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	data := strings.Repeat("data", 1024)
+	r := progress.NewReader(taskLog, "download", "Test download", strings.NewReader(data), int64(len(data)))
+	read, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, data, string(read))
+
+	taskLog.Stop()
+
+	assert.Contains(t, buf.String(), "✓ Test download")
+}
+
+func TestReader_ErrorsIfNotStarted(t *testing.T) {
+	t.Parallel()
+	taskLog := progress.NewProgress(nil)
+
+	r := progress.NewReader(taskLog, "download", "Test download", strings.NewReader("data"), 4)
+	_, err := io.ReadAll(r)
+	assert.EqualError(t, err, "can not push updates into progress log that has not been started")
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	target := bytes.NewBuffer(nil)
+	w := progress.NewWriter(taskLog, "upload", "Test upload", target, 0)
+	_, err := io.Copy(w, strings.NewReader("data"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "data", target.String())
+
+	w = progress.NewWriter(taskLog, "failing-upload", "Test failing upload", failingWriter{}, 4)
+	_, err = w.Write([]byte("data"))
+	assert.EqualError(t, err, "disk full")
+
+	taskLog.Stop()
+
+	output := buf.String()
+	assert.Contains(t, output, "✓ Test upload")
+	assert.Contains(t, output, "✗ Test failing upload"+strings.Repeat(" ", 79)+"\n  disk full\n")
+}
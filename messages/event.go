@@ -0,0 +1,84 @@
+package messages
+
+// EventType defines the kind of change in the state of a message.
+type EventType string
+
+const (
+	// EventCreated is emitted when a message is added to MessageStore.
+	EventCreated EventType = "created"
+	// EventStarted is emitted when the status of a message changes to started.
+	EventStarted EventType = "started"
+	// EventProgressChanged is emitted when progress message or numeric progress of a message changes.
+	EventProgressChanged EventType = "progress_changed"
+	// EventDetailsChanged is emitted when details of a message change.
+	EventDetailsChanged EventType = "details_changed"
+	// EventFinished is emitted when a message finishes.
+	EventFinished EventType = "finished"
+)
+
+// Event is a change in the state of a message. Message is a snapshot of the message after the change.
+type Event struct {
+	Type    EventType
+	Message Message
+}
+
+type subscriber struct {
+	fn func(Event)
+}
+
+// getEvents determines the events caused by a change from prev to msg. Use nil prev for new messages.
+func getEvents(prev *Message, msg *Message) []Event {
+	if prev == nil {
+		prev = &Message{}
+	}
+
+	var types []EventType
+	if prev.Key == "" {
+		types = append(types, EventCreated)
+	}
+	if msg.Status == MessageStatusStarted && prev.Status != MessageStatusStarted {
+		types = append(types, EventStarted)
+	}
+	if msg.ProgressMessage != prev.ProgressMessage || msg.Current != prev.Current || msg.Total != prev.Total {
+		types = append(types, EventProgressChanged)
+	}
+	if msg.Details != prev.Details {
+		types = append(types, EventDetailsChanged)
+	}
+	if msg.Status.IsFinished() && !prev.Status.IsFinished() {
+		types = append(types, EventFinished)
+	}
+
+	events := make([]Event, 0, len(types))
+	for _, eventType := range types {
+		events = append(events, Event{Type: eventType, Message: *msg})
+	}
+	return events
+}
+
+// Subscribe registers fn to be called with each event emitted by MessageStore. fn is called synchronously while the MessageStore is being modified and, thus, it must not modify the MessageStore. Returns a function that unregisters fn.
+func (ms *MessageStore) Subscribe(fn func(Event)) func() {
+	sub := &subscriber{fn: fn}
+	ms.subscribers = append(ms.subscribers, sub)
+
+	return func() {
+		for i, s := range ms.subscribers {
+			if s == sub {
+				ms.subscribers = append(ms.subscribers[:i:i], ms.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (ms *MessageStore) emit(prev *Message, msg *Message) {
+	if len(ms.subscribers) == 0 {
+		return
+	}
+
+	for _, event := range getEvents(prev, msg) {
+		for _, sub := range ms.subscribers {
+			sub.fn(event)
+		}
+	}
+}
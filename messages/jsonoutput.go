@@ -0,0 +1,155 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// OutputFormat defines the format MessageStore is rendered in.
+type OutputFormat string
+
+const (
+	// OutputFormatHuman renders messages as human-readable text with animations in interactive terminals.
+	OutputFormatHuman OutputFormat = "human"
+	// OutputFormatJSON renders messages as JSON Lines: one JSON object for each change in the state of a message.
+	OutputFormatJSON OutputFormat = "json"
+)
+
+type jsonMessage struct {
+	Key             string        `json:"key"`
+	ParentKey       string        `json:"parent_key,omitempty"`
+	Message         string        `json:"message"`
+	Status          MessageStatus `json:"status"`
+	ProgressMessage string        `json:"progress_message,omitempty"`
+	Details         string        `json:"details,omitempty"`
+	Current         int64         `json:"current,omitempty"`
+	Total           int64         `json:"total,omitempty"`
+	Unit            ProgressUnit  `json:"unit,omitempty"`
+	Attempt         int           `json:"attempt,omitempty"`
+	MaxAttempts     int           `json:"max_attempts,omitempty"`
+	Created         *time.Time    `json:"created,omitempty"`
+	Started         *time.Time    `json:"started,omitempty"`
+	Finished        *time.Time    `json:"finished,omitempty"`
+	Updated         *time.Time    `json:"updated,omitempty"`
+	ElapsedSeconds  float64       `json:"elapsed_seconds"`
+}
+
+type jsonOutput struct {
+	Output string `json:"output"`
+}
+
+type jsonSummary struct {
+	StatusCounts   map[MessageStatus]int `json:"status_counts"`
+	ElapsedSeconds float64               `json:"elapsed_seconds"`
+	ExitCode       int                   `json:"exit_code"`
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func newJSONMessage(msg Message) jsonMessage {
+	// Use the time of the change as end time of unfinished messages to output the elapsed time at the time of the change.
+	elapsed := 0.0
+	if !msg.Started.IsZero() {
+		end := msg.Finished
+		if end.IsZero() {
+			end = msg.Updated
+		}
+		elapsed = end.Sub(msg.Started).Seconds()
+	}
+
+	return jsonMessage{
+		Key:             msg.Key,
+		ParentKey:       msg.ParentKey,
+		Message:         msg.Message,
+		Status:          msg.Status,
+		ProgressMessage: msg.ProgressMessage,
+		Details:         msg.Details,
+		Current:         msg.Current,
+		Total:           msg.Total,
+		Unit:            msg.Unit,
+		Attempt:         msg.Attempt,
+		MaxAttempts:     msg.MaxAttempts,
+		Created:         timePtr(msg.Created),
+		Started:         timePtr(msg.Started),
+		Finished:        timePtr(msg.Finished),
+		Updated:         timePtr(msg.Updated),
+		ElapsedSeconds:  elapsed,
+	}
+}
+
+// JSONRenderer renders changes in MessageStore as JSON Lines. Changes are tracked with MessageStore.TrackChanges, which must be enabled before pushing updates to the MessageStore.
+type JSONRenderer struct {
+	config OutputConfig
+}
+
+func NewJSONRenderer(config OutputConfig) *JSONRenderer {
+	return &JSONRenderer{config: config}
+}
+
+func (jr JSONRenderer) write(value any) {
+	line, err := json.Marshal(value)
+	if err != nil {
+		// All rendered values can be marshaled, so this should never happen.
+		panic(fmt.Sprintf("failed to marshal JSON output: %v", err))
+	}
+	fmt.Fprintf(jr.config.Target, "%s\n", line)
+}
+
+// Target returns the writer JSONRenderer writes into.
+func (jr *JSONRenderer) Target() io.Writer {
+	return jr.config.Target
+}
+
+// SetTarget sets the writer JSONRenderer writes into.
+func (jr *JSONRenderer) SetTarget(target io.Writer) {
+	jr.config.Target = target
+}
+
+// TrackChanges returns true as JSONRenderer renders changes tracked by MessageStore.
+func (jr *JSONRenderer) TrackChanges() bool {
+	return true
+}
+
+// Render renders changes in MessageStore and, if final is true, its summary.
+func (jr *JSONRenderer) Render(ms *MessageStore, final bool) {
+	jr.RenderMessageStore(ms)
+	if final {
+		jr.RenderSummary(ms)
+	}
+}
+
+// RenderMessageStore writes a JSON object for each line of output and for each change in MessageStore since the previous render.
+func (jr *JSONRenderer) RenderMessageStore(ms *MessageStore) {
+	for _, line := range ms.TakeOutput() {
+		jr.write(jsonOutput{Output: line})
+	}
+
+	for _, msg := range ms.TakeChanges() {
+		jr.write(newJSONMessage(msg))
+	}
+}
+
+// RenderSummary writes a JSON object with the number of messages per status, elapsed time, and exit code, if summary is enabled in the output configuration.
+func (jr *JSONRenderer) RenderSummary(ms *MessageStore) {
+	if !jr.config.ShowSummary {
+		return
+	}
+
+	summary := ms.Summary(0)
+	jr.write(struct {
+		Summary jsonSummary `json:"summary"`
+	}{
+		Summary: jsonSummary{
+			StatusCounts:   summary.StatusCounts,
+			ElapsedSeconds: summary.Elapsed.Seconds(),
+			ExitCode:       summary.ExitCode(),
+		},
+	})
+}
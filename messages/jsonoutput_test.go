@@ -0,0 +1,79 @@
+package messages_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/UpCloudLtd/progress/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseJSONLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var objects []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var object map[string]any
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &object))
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+func TestJSONRenderer_RenderMessageStore(t *testing.T) {
+	t.Parallel()
+	cfg := messages.GetDefaultOutputConfig()
+	cfg.ShowSummary = true
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	renderer := messages.NewJSONRenderer(cfg)
+	store := messages.NewMessageStore()
+	store.TrackChanges()
+
+	assert.NoError(t, store.Push(messages.Update{Key: "dependency", Message: "Test dependency", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, store.Push(messages.Update{Key: "dependent", Message: "Test dependent", Status: messages.MessageStatusPending, DependsOn: []string{"dependency"}}))
+	store.AppendOutput("Test output\n")
+	renderer.RenderMessageStore(store)
+
+	// Both changes are rendered even though they happen between renders.
+	assert.NoError(t, store.Push(messages.Update{Key: "dependency", ProgressMessage: "(50 %)"}))
+	assert.NoError(t, store.Push(messages.Update{Key: "dependency", Status: messages.MessageStatusError, Details: "test error"}))
+	renderer.RenderMessageStore(store)
+	renderer.RenderSummary(store)
+
+	objects := parseJSONLines(t, buf)
+	assert.Len(t, objects, 7)
+	assert.Equal(t, "Test output", objects[0]["output"])
+
+	for i, expected := range []struct {
+		key             string
+		status          messages.MessageStatus
+		progressMessage any
+		details         any
+	}{
+		{key: "dependency", status: messages.MessageStatusStarted},
+		{key: "dependent", status: messages.MessageStatusPending},
+		{key: "dependency", status: messages.MessageStatusStarted, progressMessage: "(50 %)"},
+		{key: "dependency", status: messages.MessageStatusError, details: "test error"},
+		{key: "dependent", status: messages.MessageStatusSkipped, details: `Skipped because dependency "Test dependency" finished with error status`},
+	} {
+		object := objects[i+1]
+		assert.Equal(t, expected.key, object["key"])
+		assert.Equal(t, string(expected.status), object["status"])
+		assert.Equal(t, expected.progressMessage, object["progress_message"])
+		assert.Equal(t, expected.details, object["details"])
+		assert.Contains(t, object, "created")
+		assert.Contains(t, object, "elapsed_seconds")
+	}
+	assert.NotContains(t, objects[2], "started")
+	assert.Contains(t, objects[4], "finished")
+
+	summary, ok := objects[6]["summary"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, float64(messages.ExitCodeError), summary["exit_code"])
+	assert.Equal(t, map[string]any{"error": float64(1), "skipped": float64(1)}, summary["status_counts"])
+}
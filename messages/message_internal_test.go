@@ -0,0 +1,33 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_Rate_ETA(t *testing.T) {
+	t.Parallel()
+	started := time.Now().Add(-time.Second * 10)
+	msg := &Message{Status: MessageStatusStarted, Started: started, Total: 1000}
+	assert.Equal(t, 0.0, msg.Rate())
+	assert.Equal(t, time.Duration(0), msg.ETA())
+
+	// First measurement is used as is.
+	msg.updateRate(100, started.Add(time.Second*10))
+	msg.Current = 100
+	assert.InDelta(t, 10.0, msg.Rate(), 0.001)
+
+	// Later measurements are smoothed.
+	msg.updateRate(300, started.Add(time.Second*20))
+	msg.Current = 300
+	assert.Greater(t, msg.Rate(), 10.0)
+	assert.Less(t, msg.Rate(), 20.0)
+
+	msg.rateUpdated = time.Now()
+	assert.InDelta(t, (700 / msg.Rate()), msg.ETA().Seconds(), 0.1)
+
+	msg.Finished = time.Now()
+	assert.Equal(t, time.Duration(0), msg.ETA())
+}
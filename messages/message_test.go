@@ -1,6 +1,9 @@
 package messages_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -95,3 +98,344 @@ func TestMessageStore_Push_UpdatesMessage(t *testing.T) {
 	assert.True(t, toc.Before(msg.Finished))
 	assert.Equal(t, msg.Details, "Test details")
 }
+
+func TestMessageStore_Push_ParentKey(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "parent", Message: "Parent", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "child-1", ParentKey: "parent", Message: "Child 1", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "child-2", ParentKey: "parent", Message: "Child 2", Status: messages.MessageStatusWarning}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "grandchild", ParentKey: "child-1", Message: "Grandchild", Status: messages.MessageStatusStarted}))
+
+	err := ms.Push(messages.Update{Key: "child-1", ParentKey: "grandchild"})
+	assert.EqualError(t, err, `can not push message "child-1" with itself as its ancestor`)
+
+	children := ms.ListChildren("parent")
+	assert.Len(t, children, 2)
+	assert.Equal(t, "Child 2", children[0].Message)
+	assert.Equal(t, "Child 1", children[1].Message)
+
+	assert.Equal(t, messages.MessageStatusWarning, ms.GetStatusFromChildren("parent"))
+	assert.Equal(t, messages.MessageStatusSuccess, ms.GetStatusFromChildren("child-1"))
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "child-1", Status: messages.MessageStatusError}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "parent", StatusFromChildren: true}))
+	assert.Equal(t, messages.MessageStatusError, ms.ListFinished()[2].Status)
+}
+
+func TestMessageStore_GetStatusFromChildren(t *testing.T) {
+	t.Parallel()
+	for _, status := range []messages.MessageStatus{messages.MessageStatusError, messages.MessageStatusCancelled, messages.MessageStatusUnknown} {
+		ms := messages.NewMessageStore()
+		assert.NoError(t, ms.Push(messages.Update{Key: "parent", Message: "Parent", Status: messages.MessageStatusStarted}))
+		assert.NoError(t, ms.Push(messages.Update{Key: "child", ParentKey: "parent", Message: "Child", Status: status}))
+		assert.Equal(t, messages.MessageStatusError, ms.GetStatusFromChildren("parent"), "child with %s status", status)
+	}
+
+	ms := messages.NewMessageStore()
+	assert.NoError(t, ms.Push(messages.Update{Key: "parent", Message: "Parent", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "child-1", ParentKey: "parent", Message: "Child 1", Status: messages.MessageStatusWarning}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "child-2", ParentKey: "parent", Message: "Child 2", Status: messages.MessageStatusStarted}))
+
+	// Unfinished children are ignored when deriving the status, but prevent finishing the parent.
+	assert.Equal(t, messages.MessageStatusWarning, ms.GetStatusFromChildren("parent"))
+	err := ms.Push(messages.Update{Key: "parent", StatusFromChildren: true})
+	assert.EqualError(t, err, `can not finish message "parent" with status derived from its children before child "child-2" has finished`)
+	assert.Equal(t, messages.MessageStatusStarted, ms.GetMessage("parent").Status)
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "child-2", Status: messages.MessageStatusSuccess}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "parent", StatusFromChildren: true}))
+	assert.Equal(t, messages.MessageStatusWarning, ms.GetMessage("parent").Status)
+}
+
+func TestMessageStore_Push_DependsOn(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "network", Message: "Create network", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "storage", Message: "Create storage", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "server", Message: "Create server", Status: messages.MessageStatusPending, DependsOn: []string{"network", "storage"}}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "deploy", Message: "Deploy app", Status: messages.MessageStatusPending, DependsOn: []string{"server"}}))
+
+	// Started message is kept pending until its dependencies have finished.
+	assert.NoError(t, ms.Push(messages.Update{Key: "server", Status: messages.MessageStatusStarted}))
+	assert.Equal(t, messages.MessageStatusPending, ms.GetMessage("server").Status)
+
+	err := ms.Push(messages.Update{Key: "server", Status: messages.MessageStatusSuccess})
+	assert.EqualError(t, err, `can not push message "server" with status "success" before its dependencies have finished`)
+
+	err = ms.Push(messages.Update{Key: "self", Message: "Self", Status: messages.MessageStatusPending, DependsOn: []string{"self"}})
+	assert.EqualError(t, err, `can not push message "self" that depends on itself`)
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "network", Status: messages.MessageStatusSuccess}))
+	assert.Equal(t, messages.MessageStatusPending, ms.GetMessage("server").Status)
+
+	// Failed dependency skips dependents transitively.
+	assert.NoError(t, ms.Push(messages.Update{Key: "storage", Status: messages.MessageStatusError}))
+	assert.Len(t, ms.ListInProgress(), 0)
+
+	finished := ms.ListFinished()
+	assert.Len(t, finished, 4)
+	assert.Equal(t, "Create server", finished[2].Message)
+	assert.Equal(t, messages.MessageStatusSkipped, finished[2].Status)
+	assert.Equal(t, `Skipped because dependency "Create storage" finished with error status`, finished[2].Details)
+	assert.Equal(t, "Deploy app", finished[3].Message)
+	assert.Equal(t, messages.MessageStatusSkipped, finished[3].Status)
+	assert.Equal(t, `Skipped because dependency "Create server" finished with skipped status`, finished[3].Details)
+
+	// Messages depending on failed messages are skipped immediately.
+	assert.NoError(t, ms.Push(messages.Update{Key: "cleanup", Message: "Cleanup", Status: messages.MessageStatusPending, DependsOn: []string{"storage"}}))
+	assert.Equal(t, messages.MessageStatusSkipped, ms.ListFinished()[4].Status)
+}
+
+func TestMessageStore_Push_DependsOn_StartsWhenDependenciesFinish(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "network", Message: "Create network", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "storage", Message: "Create storage", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "server", Message: "Create server", Status: messages.MessageStatusStarted, DependsOn: []string{"network", "storage"}}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "deploy", Message: "Deploy app", Status: messages.MessageStatusPending, DependsOn: []string{"server"}}))
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "network", Status: messages.MessageStatusSuccess}))
+	server := ms.GetMessage("server")
+	assert.Equal(t, messages.MessageStatusPending, server.Status)
+	assert.True(t, server.Started.IsZero())
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "storage", Status: messages.MessageStatusWarning}))
+	server = ms.GetMessage("server")
+	assert.Equal(t, messages.MessageStatusStarted, server.Status)
+	assert.False(t, server.Started.IsZero())
+
+	// Messages that were not started are kept pending.
+	assert.NoError(t, ms.Push(messages.Update{Key: "server", Status: messages.MessageStatusSuccess}))
+	assert.Equal(t, messages.MessageStatusPending, ms.GetMessage("deploy").Status)
+}
+
+func TestMessageStore_Push_FailedAttempt(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Message: "Testing", Status: messages.MessageStatusStarted, MaxAttempts: 3}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", FailedAttempt: "timeout"}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", FailedAttempt: "service unavailable"}))
+
+	msg := ms.ListInProgress()[0]
+	assert.Equal(t, 3, msg.Attempt)
+	assert.Equal(t, 3, msg.MaxAttempts)
+	assert.Len(t, msg.Attempts, 2)
+	assert.Equal(t, 1, msg.Attempts[0].Number)
+	assert.Equal(t, "timeout", msg.Attempts[0].Error)
+	assert.Equal(t, 2, msg.Attempts[1].Number)
+	assert.Equal(t, "service unavailable", msg.Attempts[1].Error)
+}
+
+func TestMessageStore_ExpireOverdue(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	err := ms.Push(messages.Update{Key: "invalid", Message: "Invalid", Status: messages.MessageStatusStarted, TimeoutStatus: messages.MessageStatusStarted})
+	assert.EqualError(t, err, `can not push message with invalid timeout status "started"`)
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "error", Message: "Test error", Status: messages.MessageStatusStarted, Timeout: time.Second * 30}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "warning", Message: "Test warning", Status: messages.MessageStatusStarted, Timeout: time.Minute, TimeoutStatus: messages.MessageStatusWarning}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "pending", Message: "Test pending", Status: messages.MessageStatusPending, Timeout: time.Second}))
+
+	assert.Len(t, ms.ExpireOverdue(time.Now()), 0)
+
+	expired := ms.ExpireOverdue(time.Now().Add(time.Second * 45))
+	assert.Len(t, expired, 1)
+	assert.Equal(t, "Test error", expired[0].Message)
+	assert.Equal(t, messages.MessageStatusError, expired[0].Status)
+	assert.Equal(t, "Timed out after 30 s", expired[0].Details)
+	assert.True(t, expired[0].TimedOut)
+	assert.ErrorIs(t, expired[0].Err, messages.ErrTimedOut)
+
+	err = ms.Push(messages.Update{Key: "error", Status: messages.MessageStatusSuccess})
+	assert.EqualError(t, err, `can not push updates into message "error" that has already finished`)
+
+	expired = ms.ExpireOverdue(time.Now().Add(time.Hour))
+	assert.Len(t, expired, 1)
+	assert.Equal(t, messages.MessageStatusWarning, expired[0].Status)
+	assert.Equal(t, "Timed out after 60 s", expired[0].Details)
+
+	// Pending messages do not time out.
+	assert.Len(t, ms.ListInProgress(), 1)
+}
+
+func TestMessageStore_ListStalled(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	assert.NoError(t, ms.Add(messages.Message{Message: "Test stalled", Status: messages.MessageStatusStarted, Updated: time.Now().Add(-time.Minute * 2)}))
+	assert.NoError(t, ms.Add(messages.Message{Message: "Test pending", Status: messages.MessageStatusPending, Updated: time.Now().Add(-time.Minute * 2)}))
+	assert.NoError(t, ms.Push(messages.Update{Message: "Test updated", Status: messages.MessageStatusStarted}))
+
+	stalled := ms.ListStalled(time.Minute)
+	assert.Len(t, stalled, 1)
+	assert.Equal(t, "Test stalled", stalled[0].Message)
+	assert.True(t, stalled[0].IsStalled(time.Minute))
+	assert.False(t, stalled[0].IsStalled(time.Minute*5))
+	assert.False(t, stalled[0].IsStalled(0))
+
+	assert.NoError(t, ms.Push(messages.Update{Message: "Test stalled", ProgressMessage: "(still running)"}))
+	assert.Len(t, ms.ListStalled(time.Minute), 0)
+}
+
+func TestMessageStore_Push_Log(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Message: "Testing", Status: messages.MessageStatusStarted, ProgressMessage: "(1/2)"}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Log: "first line\n"}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Log: "second line\nthird line"}))
+
+	msg := ms.ListInProgress()[0]
+	assert.Equal(t, []string{"first line", "second line", "third line"}, msg.Log)
+	assert.Equal(t, "(1/2)", msg.ProgressMessage)
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "test"}))
+	assert.Equal(t, "", ms.ListInProgress()[0].ProgressMessage)
+}
+
+func TestMessageStore_AppendOutput(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	ms.AppendOutput("first line\nsecond")
+	assert.Equal(t, []string{"first line"}, ms.TakeOutput())
+	assert.Empty(t, ms.TakeOutput())
+
+	ms.AppendOutput(" line\n\nincomplete")
+	assert.Equal(t, []string{"second line", ""}, ms.TakeOutput())
+
+	ms.Close()
+	assert.Equal(t, []string{"incomplete"}, ms.TakeOutput())
+}
+
+func TestMessageStore_Summary(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+	now := time.Now()
+
+	for _, msg := range []messages.Message{
+		{Message: "Test success", Status: messages.MessageStatusSuccess, Created: now, Started: now, Finished: now.Add(time.Second * 10)},
+		{Message: "Test slow warning", Status: messages.MessageStatusWarning, Created: now, Started: now, Finished: now.Add(time.Minute)},
+		{Message: "Test error", Status: messages.MessageStatusError, Created: now, Started: now.Add(time.Second), Finished: now.Add(time.Second * 5)},
+		{Message: "Test skipped", Status: messages.MessageStatusSkipped, Created: now.Add(-time.Second), Finished: now},
+		{Message: "Test cancelled", Status: messages.MessageStatusCancelled, Created: now, Started: now, Finished: now.Add(time.Second * 2)},
+	} {
+		assert.NoError(t, ms.Add(msg))
+	}
+
+	summary := ms.Summary(2)
+	assert.Equal(t, map[messages.MessageStatus]int{
+		messages.MessageStatusSuccess:   1,
+		messages.MessageStatusWarning:   1,
+		messages.MessageStatusError:     1,
+		messages.MessageStatusSkipped:   1,
+		messages.MessageStatusCancelled: 1,
+	}, summary.StatusCounts)
+	assert.Equal(t, time.Second*61, summary.Elapsed)
+	assert.Len(t, summary.Slowest, 2)
+	assert.Equal(t, "Test slow warning", summary.Slowest[0].Message)
+	assert.Equal(t, "Test success", summary.Slowest[1].Message)
+	assert.Len(t, summary.Errors, 1)
+	assert.Equal(t, "Test error", summary.Errors[0].Message)
+	assert.Len(t, summary.Cancelled, 1)
+	assert.Equal(t, "Test cancelled", summary.Cancelled[0].Message)
+	assert.Len(t, summary.Warnings, 1)
+	assert.Equal(t, "Test slow warning", summary.Warnings[0].Message)
+
+	assert.Len(t, messages.NewMessageStore().Summary(3).StatusCounts, 0)
+}
+
+func TestMessageStore_Err(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+	assert.NoError(t, ms.Err())
+
+	errTest := errors.New("test error")
+	assert.NoError(t, ms.Push(messages.Update{Message: "Test success", Status: messages.MessageStatusSuccess}))
+	assert.NoError(t, ms.Push(messages.Update{Message: "Test warning", Status: messages.MessageStatusWarning}))
+	assert.NoError(t, ms.Push(messages.Update{Message: "Test error", Status: messages.MessageStatusError, Err: errTest}))
+	assert.NoError(t, ms.Push(messages.Update{Message: "Test started", Status: messages.MessageStatusStarted}))
+	assert.Equal(t, messages.ExitCodeWarning, messages.Summary{StatusCounts: map[messages.MessageStatus]int{messages.MessageStatusWarning: 1}}.ExitCode())
+	assert.Equal(t, messages.ExitCodeError, ms.Summary(0).ExitCode())
+	assert.Equal(t, "test error", ms.ListFinished()[2].Details)
+
+	ms.Cancel(context.Canceled)
+
+	err := ms.Err()
+	assert.EqualError(t, err, "Test error: test error\nTest started: context canceled")
+	assert.ErrorIs(t, err, errTest)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	var msgErr *messages.MessageError
+	assert.ErrorAs(t, err, &msgErr)
+	assert.Equal(t, "Test error", msgErr.Key)
+	assert.Equal(t, messages.MessageStatusError, msgErr.Status)
+}
+
+func TestMessageStore_Subscribe(t *testing.T) {
+	t.Parallel()
+	ms := messages.NewMessageStore()
+
+	var events []messages.Event
+	unsubscribe := ms.Subscribe(func(event messages.Event) {
+		events = append(events, event)
+	})
+
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Message: "Testing", Status: messages.MessageStatusPending}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Status: messages.MessageStatusStarted, ProgressMessage: "(0 %)"}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Current: 50, Total: 100}))
+	assert.NoError(t, ms.Push(messages.Update{Key: "test", Status: messages.MessageStatusError, Details: "test error"}))
+	unsubscribe()
+	assert.NoError(t, ms.Push(messages.Update{Key: "other", Message: "Other", Status: messages.MessageStatusStarted}))
+
+	var types []messages.EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []messages.EventType{
+		messages.EventCreated,
+		messages.EventStarted,
+		messages.EventProgressChanged,
+		messages.EventProgressChanged,
+		messages.EventDetailsChanged,
+		messages.EventFinished,
+	}, types)
+	assert.Equal(t, "(0 %)", events[2].Message.ProgressMessage)
+	assert.Equal(t, int64(50), events[3].Message.Current)
+	assert.Equal(t, messages.MessageStatusError, events[5].Message.Status)
+}
+
+func TestUpdate_JSON_Timeout(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		input    string
+		expected time.Duration
+	}{
+		{input: `{"key":"test"}`, expected: 0},
+		{input: `{"key":"test","timeout":"1m30s"}`, expected: time.Second * 90},
+		{input: `{"key":"test","timeout":30}`, expected: time.Second * 30},
+		{input: `{"key":"test","timeout":0.5}`, expected: time.Millisecond * 500},
+	} {
+		var update messages.Update
+		assert.NoError(t, json.Unmarshal([]byte(test.input), &update), test.input)
+		assert.Equal(t, "test", update.Key)
+		assert.Equal(t, test.expected, update.Timeout, test.input)
+	}
+
+	var update messages.Update
+	assert.EqualError(t, json.Unmarshal([]byte(`{"timeout":"soon"}`), &update), `invalid timeout "soon": time: invalid duration "soon"`)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"timeout":true}`), &update), "invalid timeout true: timeout must be a duration string or number of seconds")
+
+	data, err := json.Marshal(messages.Update{Key: "test", Timeout: time.Second * 90, TimeoutStatus: messages.MessageStatusWarning})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"key":"test","timeout_status":"warning","timeout":"1m30s"}`, string(data))
+
+	assert.NoError(t, json.Unmarshal(data, &update))
+	assert.Equal(t, time.Second*90, update.Timeout)
+}
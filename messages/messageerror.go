@@ -0,0 +1,51 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTimedOut is the error of messages that have been finished because their timeout expired.
+var ErrTimedOut = errors.New("progress message timed out")
+
+// MessageError is the error of a message that finished with error, cancelled, or unknown status. It unwraps to the error given in Err field of the update that finished the message, if any.
+type MessageError struct {
+	Key     string
+	Message string
+	Status  MessageStatus
+	Details string
+	Err     error
+}
+
+func (e *MessageError) Error() string {
+	if e.Details == "" {
+		return fmt.Sprintf("%s: %s", e.Message, e.Status)
+	}
+	return fmt.Sprintf("%s: %s", e.Message, e.Details)
+}
+
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
+
+// isFailure returns true for statuses of messages that did not finish successfully. Unknown status is a failure, as it is used for messages that were still in progress when the progress log was stopped.
+func (status MessageStatus) isFailure() bool {
+	return status == MessageStatusError || status == MessageStatusCancelled || status == MessageStatusUnknown
+}
+
+// Err returns the errors of messages that have finished with error, cancelled, or unknown status joined with errors.Join in the order the messages finished. Each of the joined errors is a *MessageError. Returns nil, if no messages have failed.
+func (ms *MessageStore) Err() error {
+	var errs []error
+	for _, msg := range ms.ListFinished() {
+		if msg.Status.isFailure() {
+			errs = append(errs, &MessageError{
+				Key:     msg.Key,
+				Message: msg.Message,
+				Status:  msg.Status,
+				Details: msg.Details,
+				Err:     msg.Err,
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
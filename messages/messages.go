@@ -1,30 +1,135 @@
 package messages
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Update struct {
-	Key             string
-	Message         string
-	Status          MessageStatus
-	ProgressMessage string
-	Details         string
+	Key             string        `json:"key,omitempty"`
+	ParentKey       string        `json:"parent_key,omitempty"`
+	Message         string        `json:"message,omitempty"`
+	Status          MessageStatus `json:"status,omitempty"`
+	ProgressMessage string        `json:"progress_message,omitempty"`
+	Details         string        `json:"details,omitempty"`
+	// Err is the error that caused the message to fail. If Details is empty, the error message is used as details. Err is not included in the JSON encoding of the update.
+	Err error `json:"-"`
+	// DependsOn lists keys of messages that must finish before the message can be started. If any of the dependencies fails, the message is skipped.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Current and Total define numeric progress of the message. Zero values leave the previous values unchanged.
+	Current int64        `json:"current,omitempty"`
+	Total   int64        `json:"total,omitempty"`
+	Unit    ProgressUnit `json:"unit,omitempty"`
+	// Attempt and MaxAttempts define the current attempt of a retried operation. Zero values leave the previous values unchanged.
+	Attempt     int `json:"attempt,omitempty"`
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// Log appends line(s) to the log of the message. Updates that only append to the log do not clear the progress message.
+	Log string `json:"log,omitempty"`
+	// FailedAttempt adds an attempt that failed with given error to the attempt history of the message and increments Attempt.
+	FailedAttempt string `json:"failed_attempt,omitempty"`
+	// Timeout defines how long the message can be in started state before it is automatically finished with TimeoutStatus. Zero value leaves the previous value unchanged.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// TimeoutStatus defines the status of the message when it times out. Defaults to error.
+	TimeoutStatus MessageStatus `json:"timeout_status,omitempty"`
+	// StatusFromChildren finishes the message with status derived from its children, if Status is not set. Pushing the update errors, if any of the children has not finished yet. See MessageStore.GetStatusFromChildren.
+	StatusFromChildren bool `json:"status_from_children,omitempty"`
+}
+
+// updateJSON is Update with Timeout in human-readable form, see Update.MarshalJSON and Update.UnmarshalJSON.
+type updateJSON struct {
+	updateFields
+	Timeout json.RawMessage `json:"timeout,omitempty"`
+}
+
+// updateFields has the same fields as Update, but not its JSON methods.
+type updateFields Update
+
+// MarshalJSON encodes the update as JSON with Timeout as duration string, e.g. `"30s"`.
+func (u Update) MarshalJSON() ([]byte, error) {
+	out := updateJSON{updateFields: updateFields(u)}
+	if u.Timeout != 0 {
+		out.Timeout = json.RawMessage(strconv.Quote(u.Timeout.String()))
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the update from JSON. Timeout can be given either as duration string, e.g. `"30s"` or `"1m30s"`, or as number of seconds, e.g. `30` or `1.5`.
+func (u *Update) UnmarshalJSON(data []byte) error {
+	var in updateJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*u = Update(in.updateFields)
+	u.Timeout = 0
+	if len(in.Timeout) == 0 || string(in.Timeout) == "null" {
+		return nil
+	}
+
+	var timeout string
+	if err := json.Unmarshal(in.Timeout, &timeout); err == nil {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf(`invalid timeout "%s": %w`, timeout, err)
+		}
+		u.Timeout = d
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(in.Timeout, &seconds); err != nil {
+		return fmt.Errorf("invalid timeout %s: timeout must be a duration string or number of seconds", in.Timeout)
+	}
+	u.Timeout = time.Duration(seconds * float64(time.Second))
+	return nil
 }
 
 type Message struct {
 	Key             string
+	ParentKey       string
 	Message         string
 	Status          MessageStatus
 	ProgressMessage string
 	Details         string
+	Err             error
+	DependsOn       []string
+	Current         int64
+	Total           int64
+	Unit            ProgressUnit
+	Attempt         int
+	MaxAttempts     int
+	Attempts        []Attempt
+	Log             []string
+	Timeout         time.Duration
+	TimeoutStatus   MessageStatus
+	Deadline        time.Time
+	TimedOut        bool
 	Created         time.Time
 	Started         time.Time
 	Finished        time.Time
+	Updated         time.Time
+
+	rate        float64
+	rateUpdated time.Time
+	// startDeferred is set when the message is kept pending after it was started before its dependencies had finished.
+	startDeferred bool
+}
+
+// Attempt is a failed attempt in the attempt history of a message.
+type Attempt struct {
+	Number int
+	Error  string
+	Failed time.Time
 }
 
+// rateTimeConstant defines how quickly the smoothed rate reacts to changes in the rate of numeric progress.
+const rateTimeConstant = 5 * time.Second
+
 func getMessageKey(key, message string) string {
 	if key != "" {
 		return key
@@ -39,6 +144,13 @@ func validateMessage(message string) error {
 	return nil
 }
 
+func validateTimeoutStatus(status MessageStatus) error {
+	if status != "" && !status.IsFinished() {
+		return fmt.Errorf(`can not push message with invalid timeout status "%s"`, status)
+	}
+	return nil
+}
+
 func validateStatus(status MessageStatus) error {
 	if !status.IsValid() {
 		return fmt.Errorf(`can not push message with invalid status "%s"`, status)
@@ -47,8 +159,9 @@ func validateStatus(status MessageStatus) error {
 }
 
 func (msg *Message) update(update Update) {
+	msg.Updated = time.Now()
 	if msg.Created.IsZero() {
-		msg.Created = time.Now()
+		msg.Created = msg.Updated
 	}
 	if update.Status != MessageStatusPending && msg.Started.IsZero() {
 		msg.Started = time.Now()
@@ -65,6 +178,12 @@ func (msg *Message) update(update Update) {
 	if msg.Key == "" {
 		msg.Key = getMessageKey(update.Key, update.Message)
 	}
+	if update.ParentKey != "" {
+		msg.ParentKey = update.ParentKey
+	}
+	if update.DependsOn != nil {
+		msg.DependsOn = update.DependsOn
+	}
 
 	if update.Message != "" {
 		msg.Message = update.Message
@@ -72,12 +191,73 @@ func (msg *Message) update(update Update) {
 	if update.Status != "" {
 		msg.Status = update.Status
 	}
+	if update.Err != nil {
+		msg.Err = update.Err
+		if update.Details == "" {
+			msg.Details = update.Err.Error()
+		}
+	}
 	if update.Details != "" {
 		msg.Details = update.Details
 	}
+	msg.updateNumericProgress(update)
+	msg.updateAttempts(update)
+	msg.updateTimeout(update)
+
+	if update.Log != "" {
+		msg.Log = append(msg.Log, strings.Split(strings.TrimSuffix(update.Log, "\n"), "\n")...)
+	}
+
+	// Clear progress message if it is not set in the update, unless the update only appends to the log
+	if update.Log == "" || update.ProgressMessage != "" {
+		msg.ProgressMessage = update.ProgressMessage
+	}
+}
+
+func (msg *Message) updateNumericProgress(update Update) {
+	if update.Current != 0 {
+		msg.updateRate(update.Current, time.Now())
+		msg.Current = update.Current
+	}
+	if update.Total != 0 {
+		msg.Total = update.Total
+	}
+	if update.Unit != ProgressUnitNone {
+		msg.Unit = update.Unit
+	}
+}
+
+func (msg *Message) updateAttempts(update Update) {
+	if update.FailedAttempt != "" {
+		msg.addFailedAttempt(update.FailedAttempt, time.Now())
+	}
+	if update.Attempt != 0 {
+		msg.Attempt = update.Attempt
+	}
+	if update.MaxAttempts != 0 {
+		msg.MaxAttempts = update.MaxAttempts
+	}
+}
+
+// updateTimeout updates the timeout of the message and, if the message has been started, its deadline.
+func (msg *Message) updateTimeout(update Update) {
+	if update.Timeout != 0 {
+		msg.Timeout = update.Timeout
+	}
+	if update.TimeoutStatus != "" {
+		msg.TimeoutStatus = update.TimeoutStatus
+	}
+	if msg.Timeout > 0 && !msg.Started.IsZero() {
+		msg.Deadline = msg.Started.Add(msg.Timeout)
+	}
+}
 
-	// Clear progress message if it is not set in the update
-	msg.ProgressMessage = update.ProgressMessage
+// IsStalled determines if the message is in started state and has not received updates within threshold. Zero threshold disables stall detection.
+func (msg Message) IsStalled(threshold time.Duration) bool {
+	if threshold <= 0 || !msg.Status.IsInProgress() || msg.Updated.IsZero() {
+		return false
+	}
+	return time.Since(msg.Updated) >= threshold
 }
 
 func (msg Message) ElapsedSeconds() float64 {
@@ -93,9 +273,85 @@ func (msg Message) ElapsedSeconds() float64 {
 	return end.Sub(msg.Started).Seconds()
 }
 
+// addFailedAttempt adds the current attempt to the attempt history and moves to the next attempt.
+func (msg *Message) addFailedAttempt(err string, now time.Time) {
+	number := msg.Attempt
+	if number < 1 {
+		number = 1
+	}
+
+	msg.Attempts = append(msg.Attempts, Attempt{
+		Number: number,
+		Error:  err,
+		Failed: now,
+	})
+	msg.Attempt = number + 1
+}
+
+// getProgress returns the ratio of Current to Total limited to range from 0 to 1. Returns false, if the message has no numeric progress.
+func (msg Message) getProgress() (float64, bool) {
+	if msg.Total <= 0 {
+		return 0, false
+	}
+
+	ratio := float64(msg.Current) / float64(msg.Total)
+	return math.Max(0, math.Min(ratio, 1)), true
+}
+
+// updateRate updates exponentially weighted moving average of the rate of numeric progress. Must be called before updating Current.
+func (msg *Message) updateRate(current int64, now time.Time) {
+	prev := msg.rateUpdated
+	if prev.IsZero() {
+		prev = msg.Started
+	}
+	if prev.IsZero() {
+		msg.rateUpdated = now
+		return
+	}
+
+	elapsed := now.Sub(prev)
+	if elapsed <= 0 {
+		// Accumulate progress until time difference can be measured.
+		return
+	}
+
+	rate := float64(current-msg.Current) / elapsed.Seconds()
+	if msg.rateUpdated.IsZero() {
+		msg.rate = rate
+	} else {
+		alpha := 1 - math.Exp(-elapsed.Seconds()/rateTimeConstant.Seconds())
+		msg.rate = alpha*rate + (1-alpha)*msg.rate
+	}
+	msg.rateUpdated = now
+}
+
+// Rate returns smoothed rate of numeric progress in units per second. Returns zero, if rate has not been measured yet.
+func (msg Message) Rate() float64 {
+	return msg.rate
+}
+
+// ETA returns estimated time until numeric progress reaches Total based on the smoothed rate. Returns zero, if the estimate is not available.
+func (msg Message) ETA() time.Duration {
+	if msg.rate <= 0 || msg.Total <= 0 || !msg.Finished.IsZero() {
+		return 0
+	}
+
+	remaining := float64(msg.Total-msg.Current) / msg.rate
+	eta := time.Duration(remaining*float64(time.Second)) - time.Since(msg.rateUpdated)
+	if eta < 0 {
+		return 0
+	}
+	return eta
+}
+
 type MessageStore struct {
-	inProgress map[string]*Message
-	finished   []*Message
+	inProgress    map[string]*Message
+	finished      []*Message
+	output        []string
+	partialOutput string
+	trackChanges  bool
+	changes       []Message
+	subscribers   []*subscriber
 }
 
 func NewMessageStore() *MessageStore {
@@ -104,13 +360,32 @@ func NewMessageStore() *MessageStore {
 	}
 }
 
-func (ms *MessageStore) storeMessage(msg *Message) {
+// storeMessage stores msg and emits events for the changes since prev. Use nil prev for new messages.
+func (ms *MessageStore) storeMessage(prev *Message, msg *Message) {
+	if ms.trackChanges {
+		ms.changes = append(ms.changes, *msg)
+	}
+
 	if msg.Status.IsFinished() {
 		delete(ms.inProgress, msg.Key)
 		ms.finished = append(ms.finished, msg)
 	} else {
 		ms.inProgress[msg.Key] = msg
 	}
+
+	ms.emit(prev, msg)
+}
+
+// TrackChanges enables recording the state of messages after each change, e.g., when a message is added, updated, or automatically skipped, timed out, or closed. Use TakeChanges to get the recorded states.
+func (ms *MessageStore) TrackChanges() {
+	ms.trackChanges = true
+}
+
+// TakeChanges returns the states of messages recorded after TrackChanges was called or TakeChanges was called previously, in the order the changes happened.
+func (ms *MessageStore) TakeChanges() []Message {
+	changes := ms.changes
+	ms.changes = nil
+	return changes
 }
 
 // Add existing Message to Message store. Useful for adding, for example, historical data to MessageStore. For live data, prefer Push.
@@ -123,7 +398,7 @@ func (ms *MessageStore) Add(msg Message) error {
 	}
 
 	msg.Key = getMessageKey(msg.Key, msg.Message)
-	ms.storeMessage(&msg)
+	ms.storeMessage(nil, &msg)
 
 	return nil
 }
@@ -135,8 +410,24 @@ func (ms *MessageStore) Push(update Update) error {
 		return fmt.Errorf("can not push message without key or message")
 	}
 
-	var msg *Message
-	if prev, ok := ms.inProgress[key]; !ok {
+	if err := ms.validateParent(key, update.ParentKey); err != nil {
+		return err
+	}
+	if err := validateTimeoutStatus(update.TimeoutStatus); err != nil {
+		return err
+	}
+	if update.Status == "" && update.StatusFromChildren {
+		if err := ms.validateChildrenFinished(key); err != nil {
+			return err
+		}
+		update.Status = ms.GetStatusFromChildren(key)
+	}
+
+	var msg, prev *Message
+	if existing, ok := ms.inProgress[key]; !ok {
+		if update.Message == "" && ms.GetMessage(key) != nil {
+			return fmt.Errorf(`can not push updates into message "%s" that has already finished`, key)
+		}
 		if err := validateMessage(update.Message); err != nil {
 			return err
 		}
@@ -146,14 +437,130 @@ func (ms *MessageStore) Push(update Update) error {
 
 		msg = &Message{}
 	} else {
-		msg = prev
+		msg = existing
+		snapshot := *existing
+		prev = &snapshot
+	}
+
+	dependsOn := msg.DependsOn
+	if update.DependsOn != nil {
+		dependsOn = update.DependsOn
+	}
+	startDeferred, err := ms.applyDependencies(key, dependsOn, &update)
+	if err != nil {
+		return err
 	}
 
 	msg.update(update)
-	ms.storeMessage(msg)
+	msg.startDeferred = startDeferred || (msg.startDeferred && msg.Status == MessageStatusPending)
+	ms.storeMessage(prev, msg)
+
+	if msg.Status.IsFinished() {
+		ms.updateDependents(msg)
+	}
 	return nil
 }
 
+// failsDependents determines if messages depending on a message with the status should be skipped.
+func (status MessageStatus) failsDependents() bool {
+	return status.IsFinished() && status != MessageStatusSuccess && status != MessageStatusWarning
+}
+
+// applyDependencies sets status of the update to skipped, if any of the dependencies has failed. If the update would start the message before its dependencies have finished, its status is set to pending and true is returned to start the message once the dependencies have finished. Errors, if the update would complete the message before its dependencies have finished.
+func (ms *MessageStore) applyDependencies(key string, dependsOn []string, update *Update) (bool, error) {
+	finished := true
+	for _, dependencyKey := range dependsOn {
+		if dependencyKey == key {
+			return false, fmt.Errorf(`can not push message "%s" that depends on itself`, key)
+		}
+
+		dependency := ms.GetMessage(dependencyKey)
+		if dependency == nil || !dependency.Status.IsFinished() {
+			finished = false
+			continue
+		}
+		if dependency.Status.failsDependents() {
+			update.Status = MessageStatusSkipped
+			update.Details = fmt.Sprintf(`Skipped because dependency "%s" finished with %s status`, dependency.Message, dependency.Status)
+			return false, nil
+		}
+	}
+	if finished {
+		return false, nil
+	}
+
+	switch update.Status { //nolint:exhaustive // Other statuses are allowed before dependencies have finished.
+	case MessageStatusStarted:
+		update.Status = MessageStatusPending
+		return true, nil
+	case MessageStatusSuccess, MessageStatusWarning:
+		return false, fmt.Errorf(`can not push message "%s" with status "%s" before its dependencies have finished`, key, update.Status)
+	}
+	return false, nil
+}
+
+// updateDependents updates in-progress messages that depend on given finished message: if the message failed, the dependents are skipped, otherwise dependents that were started before their dependencies had finished are started, if all of their dependencies have now finished.
+func (ms *MessageStore) updateDependents(msg *Message) {
+	for _, dependent := range ms.ListInProgress() {
+		if ms.inProgress[dependent.Key] != dependent {
+			// Already skipped by an earlier dependent
+			continue
+		}
+		if !dependent.dependsOn(msg.Key) {
+			continue
+		}
+
+		if msg.Status.failsDependents() {
+			_ = ms.Push(Update{Key: dependent.Key})
+		} else if dependent.startDeferred && ms.dependenciesFinished(dependent) {
+			_ = ms.Push(Update{Key: dependent.Key, Status: MessageStatusStarted})
+		}
+	}
+}
+
+func (msg *Message) dependsOn(key string) bool {
+	for _, dependencyKey := range msg.DependsOn {
+		if dependencyKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms *MessageStore) dependenciesFinished(msg *Message) bool {
+	for _, dependencyKey := range msg.DependsOn {
+		if dependency := ms.GetMessage(dependencyKey); dependency == nil || !dependency.Status.IsFinished() {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpireOverdue finishes started messages whose deadline has passed with their timeout status and returns the expired messages.
+func (ms *MessageStore) ExpireOverdue(now time.Time) []*Message {
+	var expired []*Message
+	for _, msg := range ms.ListInProgress() {
+		if !msg.Status.IsInProgress() || msg.Deadline.IsZero() || now.Before(msg.Deadline) {
+			continue
+		}
+
+		status := msg.TimeoutStatus
+		if status == "" {
+			status = MessageStatusError
+		}
+
+		msg.TimedOut = true
+		_ = ms.Push(Update{
+			Key:     msg.Key,
+			Status:  status,
+			Details: fmt.Sprintf("Timed out after %s s", strconv.FormatFloat(msg.Timeout.Seconds(), 'f', -1, 64)),
+			Err:     ErrTimedOut,
+		})
+		expired = append(expired, msg)
+	}
+	return expired
+}
+
 // ListInprogress lists in-progress messages in MessageStore sorted by started time.
 func (ms *MessageStore) ListInProgress() []*Message {
 	messages := make([]*Message, 0, len(ms.inProgress))
@@ -180,13 +587,148 @@ func (ms *MessageStore) ListInProgress() []*Message {
 	return messages
 }
 
+// ListChildren lists messages that have message identified by given key as their parent. Finished messages are listed first in order they were marked finished, followed by in-progress messages sorted as in ListInProgress.
+func (ms *MessageStore) ListChildren(key string) []*Message {
+	var children []*Message
+	for _, msg := range ms.ListFinished() {
+		if msg.ParentKey == key {
+			children = append(children, msg)
+		}
+	}
+	for _, msg := range ms.ListInProgress() {
+		if msg.ParentKey == key {
+			children = append(children, msg)
+		}
+	}
+	return children
+}
+
+// GetStatusFromChildren derives status for the message identified by given key from statuses of its finished children: error, if any of the children has error, cancelled, or unknown status, warning, if any of the children has warning status, and success otherwise. Children that have not finished yet are ignored.
+func (ms *MessageStore) GetStatusFromChildren(key string) MessageStatus {
+	status := MessageStatusSuccess
+	for _, child := range ms.ListChildren(key) {
+		switch {
+		case child.Status.isFailure():
+			return MessageStatusError
+		case child.Status == MessageStatusWarning:
+			status = MessageStatusWarning
+		}
+	}
+	return status
+}
+
+// validateChildrenFinished errors, if any of the children of the message identified by given key has not finished yet.
+func (ms *MessageStore) validateChildrenFinished(key string) error {
+	for _, child := range ms.ListChildren(key) {
+		if !child.Status.IsFinished() {
+			return fmt.Errorf(`can not finish message "%s" with status derived from its children before child "%s" has finished`, key, child.Key)
+		}
+	}
+	return nil
+}
+
+// GetMessage returns the in-progress message with given key or, if there is no such message, the latest finished message with given key. Returns nil, if there is no message with given key.
+func (ms *MessageStore) GetMessage(key string) *Message {
+	if msg, ok := ms.inProgress[key]; ok {
+		return msg
+	}
+	for i := len(ms.finished) - 1; i >= 0; i-- {
+		if ms.finished[i].Key == key {
+			return ms.finished[i]
+		}
+	}
+	return nil
+}
+
+func (ms *MessageStore) validateParent(key, parentKey string) error {
+	for parentKey != "" {
+		if parentKey == key {
+			return fmt.Errorf(`can not push message "%s" with itself as its ancestor`, key)
+		}
+
+		parent := ms.GetMessage(parentKey)
+		if parent == nil {
+			return nil
+		}
+		parentKey = parent.ParentKey
+	}
+	return nil
+}
+
+// ListStalled lists started messages that have not received updates within threshold sorted as in ListInProgress.
+func (ms *MessageStore) ListStalled(threshold time.Duration) []*Message {
+	var stalled []*Message
+	for _, msg := range ms.ListInProgress() {
+		if msg.IsStalled(threshold) {
+			stalled = append(stalled, msg)
+		}
+	}
+	return stalled
+}
+
+// AppendOutput queues complete lines of text to be outputted before finished messages. Incomplete last line is buffered until it is completed or MessageStore is cancelled or closed.
+func (ms *MessageStore) AppendOutput(text string) {
+	text = ms.partialOutput + text
+	lastNewline := strings.LastIndex(text, "\n")
+	if lastNewline == -1 {
+		ms.partialOutput = text
+		return
+	}
+
+	ms.output = append(ms.output, strings.Split(text[:lastNewline], "\n")...)
+	ms.partialOutput = text[lastNewline+1:]
+}
+
+func (ms *MessageStore) flushOutput() {
+	if ms.partialOutput != "" {
+		ms.output = append(ms.output, ms.partialOutput)
+		ms.partialOutput = ""
+	}
+}
+
+// TakeOutput returns complete lines of output appended after TakeOutput was called previously, in order they were appended, and removes them from MessageStore.
+func (ms *MessageStore) TakeOutput() []string {
+	output := ms.output
+	ms.output = nil
+	return output
+}
+
 // ListFinished lists finished messages in MessageStore in order they were marked finished.
 func (ms *MessageStore) ListFinished() []*Message {
 	return ms.finished
 }
 
-// Close sets status of pending messages to skipped and started message to unknown.
+// Cancel sets status of pending messages to skipped and started messages to cancelled. If cause is not nil, it is set as details of the cancelled messages.
+func (ms *MessageStore) Cancel(cause error) {
+	ms.flushOutput()
+
+	details := ""
+	if cause != nil {
+		details = cause.Error()
+	}
+
+	for _, msg := range ms.ListInProgress() {
+		if msg.Status == MessageStatusPending {
+			_ = ms.Push(Update{
+				Key:    msg.Key,
+				Status: MessageStatusSkipped,
+			})
+		}
+		if msg.Status == MessageStatusStarted {
+			_ = ms.Push(Update{
+				Key:     msg.Key,
+				Status:  MessageStatusCancelled,
+				Details: details,
+				Err:     cause,
+			})
+		}
+	}
+}
+
+// Close sets status of pending messages to skipped and started message to unknown and flushes incomplete line of output.
 func (ms *MessageStore) Close() {
+	ms.flushOutput()
+
 	for _, msg := range ms.ListInProgress() {
 		if msg.Status == MessageStatusPending {
 			_ = ms.Push(Update{
@@ -3,34 +3,37 @@ package messages
 type MessageStatus string
 
 const (
-	MessageStatusPending MessageStatus = "pending"
-	MessageStatusStarted MessageStatus = "started"
-	MessageStatusSuccess MessageStatus = "success"
-	MessageStatusWarning MessageStatus = "warning"
-	MessageStatusError   MessageStatus = "error"
-	MessageStatusSkipped MessageStatus = "skipped"
-	MessageStatusUnknown MessageStatus = "unknown"
+	MessageStatusPending   MessageStatus = "pending"
+	MessageStatusStarted   MessageStatus = "started"
+	MessageStatusSuccess   MessageStatus = "success"
+	MessageStatusWarning   MessageStatus = "warning"
+	MessageStatusError     MessageStatus = "error"
+	MessageStatusSkipped   MessageStatus = "skipped"
+	MessageStatusUnknown   MessageStatus = "unknown"
+	MessageStatusCancelled MessageStatus = "cancelled"
 )
 
 func getValidUpdateStatuses() map[MessageStatus]bool {
 	return map[MessageStatus]bool{
-		MessageStatusPending: true,
-		MessageStatusStarted: true,
-		MessageStatusSuccess: true,
-		MessageStatusWarning: true,
-		MessageStatusError:   true,
-		MessageStatusSkipped: true,
-		MessageStatusUnknown: true,
+		MessageStatusPending:   true,
+		MessageStatusStarted:   true,
+		MessageStatusSuccess:   true,
+		MessageStatusWarning:   true,
+		MessageStatusError:     true,
+		MessageStatusSkipped:   true,
+		MessageStatusUnknown:   true,
+		MessageStatusCancelled: true,
 	}
 }
 
 func getFinishedUpdateStatuses() map[MessageStatus]bool {
 	return map[MessageStatus]bool{
-		MessageStatusSuccess: true,
-		MessageStatusWarning: true,
-		MessageStatusError:   true,
-		MessageStatusSkipped: true,
-		MessageStatusUnknown: true,
+		MessageStatusSuccess:   true,
+		MessageStatusWarning:   true,
+		MessageStatusError:     true,
+		MessageStatusSkipped:   true,
+		MessageStatusUnknown:   true,
+		MessageStatusCancelled: true,
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/UpCloudLtd/progress/terminal"
 	"github.com/jedib0t/go-pretty/v6/text"
@@ -21,6 +22,9 @@ const (
 
 var whitespace = regexp.MustCompile(`\s`)
 
+// minMessageWidth is the width reserved for the message text before parts of numeric progress are dropped from rows that do not fit the terminal.
+const minMessageWidth = 20
+
 type OutputConfig struct {
 	DefaultTextWidth            int
 	DisableColors               bool
@@ -38,7 +42,32 @@ type OutputConfig struct {
 	StopWatchcolor              Color
 	ShowStopwatch               bool
 	DisableAnimations           bool
-	Target                      io.Writer
+	// ShowProgressBar, ShowPercentage, and ShowCounter define how numeric progress (Current and Total) of in-progress messages is rendered in interactive terminals. Counter is also rendered for messages with progress in bytes, if the terminal is wide enough.
+	ShowProgressBar               bool
+	ShowPercentage                bool
+	ShowCounter                   bool
+	ProgressBarWidth              int
+	ProgressBarCharacters         []string
+	FallbackProgressBarCharacters []string
+	// ShowRate and ShowETA define if rate and estimated time remaining are rendered next to the stopwatch for in-progress messages with numeric progress in interactive terminals.
+	ShowRate bool
+	ShowETA  bool
+	// LogTailLines defines how many of the latest log lines are rendered under started messages in interactive terminals. Zero disables rendering the log.
+	LogTailLines int
+	// CmdOutputTailLines defines how many of the last lines of output of a failed command run with RunCmd are included in the details of its message. Zero includes the full output.
+	CmdOutputTailLines int
+	// StallThreshold defines how long a started message can be without updates before it is marked stalled. Zero disables stall detection.
+	StallThreshold   time.Duration
+	StalledColor     Color
+	StalledIndicator string
+	// OutputFormat defines the format of the output. Defaults to human-readable text.
+	OutputFormat OutputFormat
+	// ShowSummary defines if summary of the messages is rendered when the progress log is stopped. SummarySlowestCount defines how many of the slowest messages are listed in the summary.
+	ShowSummary         bool
+	SummarySlowestCount int
+	// NonInteractiveProgressStep defines the percentage step after which in-progress message with numeric progress is printed again to non-interactive terminals. Zero disables printing progress to non-interactive terminals.
+	NonInteractiveProgressStep int
+	Target                     io.Writer
 }
 
 func GetDefaultOutputConfig() OutputConfig {
@@ -47,35 +76,55 @@ func GetDefaultOutputConfig() OutputConfig {
 		DisableColors:       false,
 		ShowStatusIndicator: true,
 		StatusIndicatorMap: map[MessageStatus]string{
-			MessageStatusSuccess: "✓", // Check mark: U+2713
-			MessageStatusWarning: "!",
-			MessageStatusError:   "✗", // Ballot X: U+2717
-			MessageStatusStarted: ">",
-			MessageStatusPending: "#",
-			MessageStatusSkipped: "-",
+			MessageStatusSuccess:   "✓", // Check mark: U+2713
+			MessageStatusWarning:   "!",
+			MessageStatusError:     "✗", // Ballot X: U+2717
+			MessageStatusStarted:   ">",
+			MessageStatusPending:   "#",
+			MessageStatusSkipped:   "-",
+			MessageStatusCancelled: "⊘", // Circled division slash: U+2298
 		},
 		FallbackStatusIndicatorMap: map[MessageStatus]string{
-			MessageStatusSuccess: "√", // Square root: U+221A
-			MessageStatusError:   "X",
+			MessageStatusSuccess:   "√", // Square root: U+221A
+			MessageStatusError:     "X",
+			MessageStatusCancelled: "x",
 		},
 		StatusColorMap: map[MessageStatus]Color{
-			MessageStatusSuccess: text.FgGreen,
-			MessageStatusWarning: text.FgYellow,
-			MessageStatusError:   text.FgRed,
-			MessageStatusStarted: text.FgBlue,
-			MessageStatusPending: text.FgCyan,
-			MessageStatusSkipped: text.FgMagenta,
+			MessageStatusSuccess:   text.FgGreen,
+			MessageStatusWarning:   text.FgYellow,
+			MessageStatusError:     text.FgRed,
+			MessageStatusStarted:   text.FgBlue,
+			MessageStatusPending:   text.FgCyan,
+			MessageStatusSkipped:   text.FgMagenta,
+			MessageStatusCancelled: text.FgHiRed,
 		},
-		InProgressAnimation:         []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
-		FallbackInProgressAnimation: []string{"/", "-", "\\", "|"},
-		UnknownColor:                text.FgWhite,
-		UnknownIndicator:            "?",
-		DetailsColor:                text.FgHiBlack,
-		ColorMessage:                false,
-		StopWatchcolor:              text.FgHiBlack,
-		ShowStopwatch:               true,
-		DisableAnimations:           false,
-		Target:                      os.Stderr,
+		InProgressAnimation:           []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+		FallbackInProgressAnimation:   []string{"/", "-", "\\", "|"},
+		UnknownColor:                  text.FgWhite,
+		UnknownIndicator:              "?",
+		DetailsColor:                  text.FgHiBlack,
+		ColorMessage:                  false,
+		StopWatchcolor:                text.FgHiBlack,
+		ShowStopwatch:                 true,
+		DisableAnimations:             false,
+		ShowProgressBar:               true,
+		ShowPercentage:                true,
+		ShowCounter:                   false,
+		ProgressBarWidth:              20,
+		ProgressBarCharacters:         []string{"█", "░"}, // Full block: U+2588, Light shade: U+2591
+		FallbackProgressBarCharacters: []string{"#", "-"},
+		ShowRate:                      true,
+		ShowETA:                       true,
+		LogTailLines:                  5,
+		CmdOutputTailLines:            0,
+		StallThreshold:                0,
+		StalledColor:                  text.FgYellow,
+		StalledIndicator:              "~",
+		OutputFormat:                  OutputFormatHuman,
+		ShowSummary:                   false,
+		SummarySlowestCount:           3,
+		NonInteractiveProgressStep:    25,
+		Target:                        os.Stderr,
 	}
 }
 
@@ -108,6 +157,16 @@ func (cfg OutputConfig) getDetailsColor() Color {
 	return cfg.getColor(cfg.DetailsColor)
 }
 
+// GetStatusColor returns the color used for messages with given status. Returns a color that does not modify the text, if colors are disabled.
+func (cfg OutputConfig) GetStatusColor(status MessageStatus) Color {
+	return cfg.getStatusColor(status)
+}
+
+// GetDetailsColor returns the color used for details of messages. Returns a color that does not modify the text, if colors are disabled.
+func (cfg OutputConfig) GetDetailsColor() Color {
+	return cfg.getDetailsColor()
+}
+
 func (cfg OutputConfig) getStopWatchcolor() Color {
 	return cfg.getColor(cfg.StopWatchcolor)
 }
@@ -137,6 +196,122 @@ func (cfg OutputConfig) getInProgressAnimationFrame(renderState RenderState) str
 	return animation[i]
 }
 
+func (cfg OutputConfig) getProgressBar(ratio float64) string {
+	chars := cfg.ProgressBarCharacters
+	if cfg.shouldUseFallback() {
+		chars = cfg.FallbackProgressBarCharacters
+	}
+	if len(chars) < 2 {
+		return ""
+	}
+
+	// Limit progress bar to quarter of the terminal width to leave room for the message.
+	width := cfg.ProgressBarWidth
+	if maxWidth := cfg.GetMaxWidth() / 4; width > maxWidth {
+		width = maxWidth
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	filled := int(ratio * float64(width))
+	return strings.Repeat(chars[0], filled) + strings.Repeat(chars[1], width-filled)
+}
+
+func percentageString(ratio float64) string {
+	return fmt.Sprintf("%3d %%", int(ratio*100))
+}
+
+// progressParts defines which parts of numeric progress are rendered with in-progress message.
+type progressParts struct {
+	bar, percentage, counter, rate, eta bool
+}
+
+// getProgressParts returns the parts of numeric progress enabled in the output configuration. Counter is always rendered for messages with progress in bytes.
+func (cfg OutputConfig) getProgressParts(msg *Message) progressParts {
+	return progressParts{
+		bar:        cfg.ShowProgressBar,
+		percentage: cfg.ShowPercentage,
+		counter:    cfg.ShowCounter || msg.Unit == ProgressUnitBytes,
+		rate:       cfg.ShowRate,
+		eta:        cfg.ShowETA,
+	}
+}
+
+// getProgressPartDroppers returns functions that drop parts of numeric progress in the order they are dropped from rows that do not fit the terminal.
+func getProgressPartDroppers() []func(*progressParts) {
+	return []func(*progressParts){
+		func(p *progressParts) { p.eta = false },
+		func(p *progressParts) { p.rate = false },
+		func(p *progressParts) { p.counter = false },
+		func(p *progressParts) { p.bar = false },
+	}
+}
+
+// getProgressText renders numeric progress of in-progress message. In non-interactive terminals, only percentage is rendered.
+func (cfg OutputConfig) getProgressText(msg *Message, isInteractive bool, show progressParts) string {
+	if !msg.Status.IsInProgress() {
+		return ""
+	}
+
+	ratio, ok := msg.getProgress()
+	if !ok {
+		// Without total, only the counter can be rendered.
+		if isInteractive && show.counter && msg.Current > 0 {
+			return " " + msg.Unit.Format(float64(msg.Current))
+		}
+		return ""
+	}
+
+	if !isInteractive {
+		if cfg.NonInteractiveProgressStep <= 0 {
+			return ""
+		}
+		return " " + percentageString(ratio)
+	}
+
+	var parts []string
+	if show.bar {
+		if bar := cfg.getProgressBar(ratio); bar != "" {
+			parts = append(parts, cfg.getStatusColor(msg.Status).Sprint(bar))
+		}
+	}
+	if show.percentage {
+		parts = append(parts, percentageString(ratio))
+	}
+	if show.counter {
+		parts = append(parts, fmt.Sprintf("%s/%s", msg.Unit.Format(float64(msg.Current)), msg.Unit.Format(float64(msg.Total))))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, " ")
+}
+
+// getProgressStep returns the percentage step the numeric progress of the message has reached. Used to determine when to print the message again to non-interactive terminals.
+func (cfg OutputConfig) getProgressStep(msg *Message) int {
+	ratio, ok := msg.getProgress()
+	if !ok || cfg.NonInteractiveProgressStep <= 0 {
+		return 0
+	}
+
+	percentage := int(ratio * 100)
+	return percentage - percentage%cfg.NonInteractiveProgressStep
+}
+
+func stallDurationString(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
+
 func elapsedString(elapsedSeconds float64) string {
 	if elapsedSeconds < 1 {
 		return ""
@@ -149,6 +324,49 @@ func elapsedString(elapsedSeconds float64) string {
 	return fmt.Sprintf("%3d s", int(elapsedSeconds))
 }
 
+func etaString(eta time.Duration) string {
+	if eta.Seconds() >= 999 {
+		return "ETA > 999 s"
+	}
+
+	return fmt.Sprintf("ETA %3d s", int(math.Ceil(eta.Seconds())))
+}
+
+// getEstimateText renders rate and estimated time remaining of in-progress message with numeric progress.
+func (cfg OutputConfig) getEstimateText(msg *Message, isInteractive bool, show progressParts) string {
+	if !isInteractive || !msg.Status.IsInProgress() {
+		return ""
+	}
+
+	var parts []string
+	if show.rate && msg.Rate() > 0 {
+		parts = append(parts, msg.Unit.FormatRate(msg.Rate()))
+	}
+	if eta := msg.ETA(); show.eta && eta > 0 {
+		parts = append(parts, etaString(eta))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return cfg.getStopWatchcolor().Sprintf(" %s", strings.Join(parts, " "))
+}
+
+// fitProgressText renders numeric progress and estimate of in-progress message. Parts of them are dropped, starting from ETA, until they fit into given width.
+func (cfg OutputConfig) fitProgressText(msg *Message, isInteractive bool, width int) (string, string) {
+	lenFn := text.RuneWidthWithoutEscSequences
+	show := cfg.getProgressParts(msg)
+	progress, estimate := cfg.getProgressText(msg, isInteractive, show), cfg.getEstimateText(msg, isInteractive, show)
+	for _, drop := range getProgressPartDroppers() {
+		if lenFn(progress)+lenFn(estimate) <= width {
+			break
+		}
+		drop(&show)
+		progress, estimate = cfg.getProgressText(msg, isInteractive, show), cfg.getEstimateText(msg, isInteractive, show)
+	}
+	return progress, estimate
+}
+
 func (cfg OutputConfig) getDimensions() (int, int) {
 	file, ok := cfg.Target.(*os.File)
 	if !ok {
@@ -180,31 +398,100 @@ func (cfg OutputConfig) GetMaxHeight() int {
 	return height
 }
 
-func (cfg OutputConfig) formatDetails(msg *Message) string {
-	wrapWidth := cfg.GetMaxWidth() - 2
+func getAttemptText(attempt, maxAttempts int) string {
+	if maxAttempts > 0 {
+		return fmt.Sprintf("attempt %d/%d", attempt, maxAttempts)
+	}
+	return fmt.Sprintf("attempt %d", attempt)
+}
+
+func (cfg OutputConfig) formatDetails(msg *Message, indent string) string {
+	wrapWidth := cfg.GetMaxWidth() - 2 - len(indent)
 
-	var details string
+	var lines []string
 	// If details contains newline characters, assume that details are preformatted (e.g., stack trace, console output, ...)
 	if strings.Contains(msg.Details, "\n") {
-		details = text.WrapText(cfg.getDetailsColor().Sprint(msg.Details), wrapWidth)
-	} else {
-		details = text.WrapSoft(cfg.getDetailsColor().Sprint(msg.Details), wrapWidth)
+		lines = append(lines, text.WrapText(cfg.getDetailsColor().Sprint(msg.Details), wrapWidth))
+	} else if msg.Details != "" {
+		lines = append(lines, text.WrapSoft(cfg.getDetailsColor().Sprint(msg.Details), wrapWidth))
+	}
+
+	// Include the full log of failed messages
+	if msg.Status == MessageStatusError && len(msg.Log) > 0 {
+		lines = append(lines, text.WrapText(cfg.getDetailsColor().Sprint(strings.Join(msg.Log, "\n")), wrapWidth))
+	}
+
+	// List errors of earlier attempts after the details
+	for _, attempt := range msg.Attempts {
+		line := fmt.Sprintf("Earlier %s failed: %s", getAttemptText(attempt.Number, msg.MaxAttempts), attempt.Error)
+		lines = append(lines, text.WrapSoft(cfg.getDetailsColor().Sprint(whitespace.ReplaceAllString(line, " ")), wrapWidth))
+	}
+
+	if len(lines) == 0 {
+		return ""
 	}
 
+	details := strings.Join(lines, "\n")
 	if cfg.ShowStatusIndicator {
-		return strings.ReplaceAll("\n"+details, "\n", "\n  ")
+		indent += "  "
 	}
-	return "\n" + details
+	return strings.ReplaceAll("\n"+details, "\n", "\n"+indent)
+}
+
+// getLogTailText renders the latest log lines of started message. Each line is rendered into a single row that spans the whole terminal width.
+func (cfg OutputConfig) getLogTailText(msg *Message, depth int) []string {
+	if cfg.LogTailLines <= 0 || !msg.Status.IsInProgress() || len(msg.Log) == 0 {
+		return nil
+	}
+
+	indent := strings.Repeat("  ", depth)
+	if cfg.ShowStatusIndicator {
+		indent += "  "
+	}
+	maxLineWidth := cfg.GetMaxWidth() - len(indent)
+	if maxLineWidth <= 0 {
+		return nil
+	}
+
+	tail := msg.Log
+	if len(tail) > cfg.LogTailLines {
+		tail = tail[len(tail)-cfg.LogTailLines:]
+	}
+
+	lines := make([]string, 0, len(tail))
+	for _, line := range tail {
+		line = whitespace.ReplaceAllString(line, " ")
+		if text.RuneWidthWithoutEscSequences(line) > maxLineWidth {
+			line = text.Trim(line, maxLineWidth-1) + "…"
+		} else {
+			line = text.Pad(line, maxLineWidth, ' ')
+		}
+		lines = append(lines, fmt.Sprintf("%s%s\n", indent, cfg.getDetailsColor().Sprint(line)))
+	}
+	return lines
 }
 
 func (cfg OutputConfig) GetMessageText(msg *Message, renderState RenderState) string {
+	return cfg.getMessageText(msg, renderState, 0, true)
+}
+
+// getMessageText renders message indented by given depth. Details of finished messages are included only if showDetails is true.
+func (cfg OutputConfig) getMessageText(msg *Message, renderState RenderState, depth int, showDetails bool) string {
+	indent := strings.Repeat("  ", depth)
 	isInteractive := cfg.GetMaxHeight() > 0
 
+	isStalled := msg.IsStalled(cfg.StallThreshold)
+
 	status := ""
 	color := cfg.getStatusColor(msg.Status)
+	if isStalled {
+		color = cfg.getColor(cfg.StalledColor)
+	}
 	if cfg.ShowStatusIndicator {
 		indicator := cfg.getStatusIndicator(msg.Status)
-		if msg.Status.IsInProgress() && isInteractive {
+		if isStalled {
+			indicator = cfg.StalledIndicator
+		} else if msg.Status.IsInProgress() && isInteractive {
 			indicator = cfg.getInProgressAnimationFrame(renderState)
 		}
 
@@ -218,15 +505,24 @@ func (cfg OutputConfig) GetMessageText(msg *Message, renderState RenderState) st
 
 	lenFn := text.RuneWidthWithoutEscSequences
 	message := msg.Message
+	if msg.Status.IsInProgress() && msg.Attempt > 1 {
+		message += fmt.Sprintf(" (%s)", getAttemptText(msg.Attempt, msg.MaxAttempts))
+	}
 	if isInteractive && msg.ProgressMessage != "" {
 		message += " " + msg.ProgressMessage
 	}
-	maxMessageWidth := cfg.GetMaxWidth() - lenFn(status) - lenFn(elapsed)
+	if isStalled {
+		message += fmt.Sprintf(" (no updates for %s)", stallDurationString(time.Since(msg.Updated)))
+	}
+	message = whitespace.ReplaceAllString(message, " ")
+
+	availableWidth := cfg.GetMaxWidth() - len(indent) - lenFn(status) - lenFn(elapsed)
+	progress, estimate := cfg.fitProgressText(msg, isInteractive, availableWidth-min(lenFn(message), minMessageWidth))
+	maxMessageWidth := availableWidth - lenFn(progress) - lenFn(estimate)
 	// Some terminals initially return 0 width, skip rendering message in that case.
-	if maxMessageWidth < 0 {
+	if maxMessageWidth <= 0 {
 		return ""
 	}
-	message = whitespace.ReplaceAllString(message, " ")
 	if len(message) > maxMessageWidth {
 		message = fmt.Sprintf("%s…", message[:maxMessageWidth-1])
 	} else {
@@ -237,15 +533,59 @@ func (cfg OutputConfig) GetMessageText(msg *Message, renderState RenderState) st
 	}
 
 	details := ""
-	if msg.Details != "" && msg.Status.IsFinished() {
-		details = cfg.formatDetails(msg)
+	if showDetails && msg.Status.IsFinished() {
+		details = cfg.formatDetails(msg, indent)
+	}
+
+	return fmt.Sprintf("%s%s%s%s%s%s%s\n", indent, status, message, progress, estimate, elapsed, details)
+}
+
+// GetSummaryText renders summary of messages: number of messages per status, elapsed time, the slowest messages, and messages with error, cancelled, or warning status with their details.
+func (cfg OutputConfig) GetSummaryText(summary Summary) string {
+	var counts []string
+	for _, status := range getSummaryStatuses() {
+		if count := summary.StatusCounts[status]; count > 0 {
+			counts = append(counts, cfg.getStatusColor(status).Sprintf("%d %s", count, status))
+		}
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+
+	// List only messages with elapsed time long enough to be shown with the stopwatch
+	var slowest []*Message
+	for _, msg := range summary.Slowest {
+		if elapsedString(msg.ElapsedSeconds()) != "" {
+			slowest = append(slowest, msg)
+		}
 	}
 
-	return fmt.Sprintf("%s%s%s%s\n", status, message, elapsed, details)
+	text := fmt.Sprintf("\nSummary: %s in %s\n", strings.Join(counts, ", "), summary.Elapsed.Round(time.Second))
+	for _, section := range []struct {
+		title       string
+		messages    []*Message
+		showDetails bool
+	}{
+		{title: "Slowest:", messages: slowest},
+		{title: "Errors:", messages: summary.Errors, showDetails: true},
+		{title: "Cancelled:", messages: summary.Cancelled, showDetails: true},
+		{title: "Warnings:", messages: summary.Warnings, showDetails: true},
+	} {
+		if len(section.messages) == 0 {
+			continue
+		}
+
+		text += section.title + "\n"
+		for _, msg := range section.messages {
+			text += cfg.getMessageText(msg, RenderStateDone, 1, section.showDetails)
+		}
+	}
+	return text
 }
 
 type MessageRenderer struct {
 	finishedMap      map[string]bool
+	renderedMap      map[*Message]bool
 	config           OutputConfig
 	renderState      RenderState
 	finishedIndex    int
@@ -256,6 +596,7 @@ type MessageRenderer struct {
 func NewMessageRenderer(config OutputConfig) *MessageRenderer {
 	return &MessageRenderer{
 		finishedMap: make(map[string]bool),
+		renderedMap: make(map[*Message]bool),
 		config:      config,
 	}
 }
@@ -264,7 +605,17 @@ func (mr MessageRenderer) write(args ...any) {
 	fmt.Fprint(mr.config.Target, args...)
 }
 
-func (mr MessageRenderer) prepareMessage(msg *Message, keyPostfix ...string) string {
+// Target returns the writer MessageRenderer writes into.
+func (mr *MessageRenderer) Target() io.Writer {
+	return mr.config.Target
+}
+
+// SetTarget sets the writer MessageRenderer writes into.
+func (mr *MessageRenderer) SetTarget(target io.Writer) {
+	mr.config.Target = target
+}
+
+func (mr MessageRenderer) prepareMessage(msg *Message, depth int, keyPostfix ...string) string {
 	key := fmt.Sprint(msg.Key, keyPostfix)
 
 	if mr.finishedMap[key] {
@@ -272,38 +623,117 @@ func (mr MessageRenderer) prepareMessage(msg *Message, keyPostfix ...string) str
 	}
 
 	mr.finishedMap[key] = true
-	return mr.config.GetMessageText(msg, mr.renderState)
+	return mr.config.getMessageText(msg, mr.renderState, depth, false)
+}
+
+// isDeferred determines if rendering finished message should be deferred until its parent is rendered.
+func (mr MessageRenderer) isDeferred(ms *MessageStore, msg *Message) bool {
+	if msg.ParentKey == "" {
+		return false
+	}
+
+	parent := ms.GetMessage(msg.ParentKey)
+	if parent == nil {
+		return false
+	}
+	return parent.Status.IsInProgress() || (parent.Status.IsFinished() && !mr.renderedMap[parent])
+}
+
+// renderFinished renders finished message and its deferred children.
+func (mr MessageRenderer) renderFinished(ms *MessageStore, msg *Message, depth int) string {
+	mr.renderedMap[msg] = true
+	text := mr.config.getMessageText(msg, mr.renderState, depth, true)
+
+	for _, child := range ms.ListChildren(msg.Key) {
+		if child.Status.IsFinished() && !mr.renderedMap[child] {
+			text += mr.renderFinished(ms, child, depth+1)
+		}
+	}
+	return text
+}
+
+type treeItem struct {
+	msg   *Message
+	depth int
+}
+
+// isListedInProgress determines if message with the status is rendered into the in-progress area of interactive terminals.
+func isListedInProgress(status MessageStatus) bool {
+	return status == MessageStatusStarted || status == MessageStatusPending
+}
+
+// listInProgressTree lists started and pending messages and deferred finished messages in the order they are rendered into the in-progress area. Started messages are listed before pending messages and children after their parent.
+func (mr MessageRenderer) listInProgressTree(ms *MessageStore) []treeItem {
+	var items []treeItem
+
+	var walk func(msg *Message, depth int)
+	walk = func(msg *Message, depth int) {
+		items = append(items, treeItem{msg: msg, depth: depth})
+		for _, child := range ms.ListChildren(msg.Key) {
+			isDeferred := child.Status.IsFinished() && !mr.renderedMap[child]
+			if isDeferred || (isListedInProgress(msg.Status) && isListedInProgress(child.Status)) {
+				walk(child, depth+1)
+			}
+		}
+	}
+
+	inProgress := ms.ListInProgress()
+	for _, status := range []MessageStatus{MessageStatusStarted, MessageStatusPending} {
+		for _, msg := range inProgress {
+			if msg.Status != status {
+				continue
+			}
+			if parent, ok := ms.inProgress[msg.ParentKey]; ok && isListedInProgress(parent.Status) {
+				continue
+			}
+			walk(msg, 0)
+		}
+	}
+	return items
 }
 
 func (mr *MessageRenderer) RenderMessageStore(ms *MessageStore) {
 	text := mr.moveToInProgressStartText()
 
-	// Render finished messages
+	// Render output written to the progress log before finished messages
+	for _, line := range ms.TakeOutput() {
+		text += line + "\n"
+	}
+
+	// Render finished messages. Children of messages that have not been rendered yet are rendered with their parent.
 	finished := ms.ListFinished()[mr.finishedIndex:]
 	for _, msg := range finished {
-		if msg.Status.IsFinished() {
-			text += mr.config.GetMessageText(msg, mr.renderState)
+		if msg.Status.IsFinished() && !mr.renderedMap[msg] && !mr.isDeferred(ms, msg) {
+			text += mr.renderFinished(ms, msg, 0)
 		}
 	}
 	mr.finishedIndex += len(finished)
 
 	// Render in-progress messages
-	inProgress := ms.ListInProgress()
 	count := 0
-	for _, msg := range inProgress {
-		if !msg.Status.IsInProgress() {
-			continue
-		}
+	for _, item := range mr.listInProgressTree(ms) {
 		maxHeight := mr.config.GetMaxHeight()
 		if maxHeight == 0 {
-			// Print message when it is started and when its message changes to new value
-			text += mr.prepareMessage(msg, msg.Message, "started")
+			// Print message when it is started, when its message changes to new value, when its numeric progress reaches next step, when it is retried, and when it stalls
+			if item.msg.Status.IsInProgress() {
+				step := fmt.Sprint(mr.config.getProgressStep(item.msg))
+				attempt := fmt.Sprint(item.msg.Attempt)
+				stalled := fmt.Sprint(item.msg.IsStalled(mr.config.StallThreshold))
+				text += mr.prepareMessage(item.msg, item.depth, item.msg.Message, "started", step, attempt, stalled)
+			}
 		} else {
-			if count >= maxHeight {
-				break
+			rows := append([]string{mr.config.getMessageText(item.msg, mr.renderState, item.depth, false)}, mr.config.getLogTailText(item.msg, item.depth)...)
+			for _, row := range rows {
+				if count >= maxHeight {
+					break
+				}
+				// Rows that do not fit the terminal are not rendered and must not be erased on the next render.
+				if row == "" {
+					continue
+				}
+				text += row
+				count++
 			}
-			text += mr.config.GetMessageText(msg, mr.renderState)
-			count++
 		}
 	}
 	if text != "" {
@@ -315,6 +745,25 @@ func (mr *MessageRenderer) RenderMessageStore(ms *MessageStore) {
 	mr.renderState++
 }
 
+// Render renders MessageStore and, if final is true, its summary.
+func (mr *MessageRenderer) Render(ms *MessageStore, final bool) {
+	mr.RenderMessageStore(ms)
+	if final {
+		mr.RenderSummary(ms)
+	}
+}
+
+// RenderSummary renders summary of the messages in MessageStore, if enabled in the output configuration. Should be called after the final state of MessageStore has been rendered.
+func (mr *MessageRenderer) RenderSummary(ms *MessageStore) {
+	if !mr.config.ShowSummary {
+		return
+	}
+
+	if text := mr.config.GetSummaryText(ms.Summary(mr.config.SummarySlowestCount)); text != "" {
+		mr.write(text)
+	}
+}
+
 func (mr *MessageRenderer) moveToInProgressStartText() string {
 	if mr.inProgressHeight == 0 {
 		return ""
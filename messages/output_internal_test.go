@@ -3,6 +3,7 @@ package messages
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -62,3 +63,224 @@ func TestMessageRenderer_moveToInProgressStartText(t *testing.T) {
 		})
 	}
 }
+
+func TestOutputConfig_getProgressText(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	cfg.ProgressBarWidth = 10
+	cfg.ShowCounter = true
+
+	msg := &Message{Status: MessageStatusStarted, Current: 45, Total: 100}
+	assert.Equal(t, " ████░░░░░░  45 % 45/100", cfg.getProgressText(msg, true, cfg.getProgressParts(msg)))
+	assert.Equal(t, "  45 %", cfg.getProgressText(msg, false, cfg.getProgressParts(msg)))
+
+	msg.Current = 150
+	assert.Equal(t, " ██████████ 100 % 150/100", cfg.getProgressText(msg, true, cfg.getProgressParts(msg)))
+
+	cfg.ShowProgressBar = false
+	cfg.ShowCounter = false
+	assert.Equal(t, " 100 %", cfg.getProgressText(msg, true, cfg.getProgressParts(msg)))
+
+	msg.Status = MessageStatusSuccess
+	assert.Equal(t, "", cfg.getProgressText(msg, true, cfg.getProgressParts(msg)))
+
+	msg = &Message{Status: MessageStatusStarted}
+	assert.Equal(t, "", cfg.getProgressText(msg, true, cfg.getProgressParts(msg)))
+
+	// Counter is rendered for progress in bytes even when ShowCounter is disabled.
+	msg = &Message{Status: MessageStatusStarted, Current: 1536, Total: 4096, Unit: ProgressUnitBytes}
+	assert.Equal(t, "  37 % 1.5 KiB/4.0 KiB", cfg.getProgressText(msg, true, cfg.getProgressParts(msg)))
+
+	msg.Total = 0
+	assert.Equal(t, " 1.5 KiB", cfg.getProgressText(msg, true, cfg.getProgressParts(msg)))
+}
+
+func TestOutputConfig_getEstimateText(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+
+	msg := &Message{Status: MessageStatusStarted, Current: 100, Total: 1000, rate: 20, rateUpdated: time.Now()}
+	assert.Equal(t, " 20.0/s ETA  45 s", cfg.getEstimateText(msg, true, cfg.getProgressParts(msg)))
+	assert.Equal(t, "", cfg.getEstimateText(msg, false, cfg.getProgressParts(msg)))
+
+	cfg.ShowETA = false
+	assert.Equal(t, " 20.0/s", cfg.getEstimateText(msg, true, cfg.getProgressParts(msg)))
+}
+
+func TestOutputConfig_fitProgressText(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	cfg.DefaultTextWidth = 80
+	cfg.ShowCounter = true
+
+	msg := &Message{Status: MessageStatusStarted, Current: 45, Total: 100, rate: 5, rateUpdated: time.Now()}
+	for _, test := range []struct {
+		width    int
+		progress string
+		estimate string
+	}{
+		{width: 60, progress: " █████████░░░░░░░░░░░  45 % 45/100", estimate: " 5.0/s ETA  11 s"},
+		{width: 45, progress: " █████████░░░░░░░░░░░  45 % 45/100", estimate: " 5.0/s"},
+		{width: 39, progress: " █████████░░░░░░░░░░░  45 % 45/100", estimate: ""},
+		{width: 30, progress: " █████████░░░░░░░░░░░  45 %", estimate: ""},
+		{width: 20, progress: "  45 %", estimate: ""},
+		{width: 0, progress: "  45 %", estimate: ""},
+	} {
+		progress, estimate := cfg.fitProgressText(msg, true, test.width)
+		assert.Equal(t, test.progress, progress, "width %d", test.width)
+		assert.Equal(t, test.estimate, estimate, "width %d", test.width)
+	}
+
+	// Counter of progress in bytes is rendered even when ShowCounter is disabled, but it is dropped like other parts.
+	cfg.ShowCounter = false
+	msg = &Message{Status: MessageStatusStarted, Current: 1536, Total: 4096, Unit: ProgressUnitBytes, rate: 1024, rateUpdated: time.Now()}
+	progress, estimate := cfg.fitProgressText(msg, true, 50)
+	assert.Equal(t, " ███████░░░░░░░░░░░░░  37 % 1.5 KiB/4.0 KiB", progress)
+	assert.Equal(t, "", estimate)
+
+	progress, _ = cfg.fitProgressText(msg, true, 40)
+	assert.Equal(t, " ███████░░░░░░░░░░░░░  37 %", progress)
+}
+
+func TestOutputConfig_getMessageText_NarrowWidth(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	cfg.NonInteractiveProgressStep = 25
+
+	msg := &Message{Message: "Uploading image", Status: MessageStatusStarted, Current: 1536, Total: 4096, Unit: ProgressUnitBytes}
+	for _, test := range []struct {
+		width    int
+		expected string
+	}{
+		{width: 24, expected: "> Uploading image   37 %\n"},
+		{width: 12, expected: "> Upl…  37 %\n"},
+		{width: 9, expected: "> …  37 %\n"},
+		{width: 8, expected: ""},
+		{width: 0, expected: ""},
+	} {
+		cfg.DefaultTextWidth = test.width
+		assert.Equal(t, test.expected, cfg.getMessageText(msg, 0, 0, false), "width %d", test.width)
+	}
+}
+
+func TestProgressUnit_Format(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		unit     ProgressUnit
+		value    float64
+		expected string
+	}{
+		{unit: ProgressUnitNone, value: 1536, expected: "1536"},
+		{unit: ProgressUnitBytes, value: 1023, expected: "1023 B"},
+		{unit: ProgressUnitBytes, value: 1536, expected: "1.5 KiB"},
+		{unit: ProgressUnitBytes, value: 5 * 1024 * 1024 * 1024, expected: "5.0 GiB"},
+	} {
+		assert.Equal(t, test.expected, test.unit.Format(test.value))
+	}
+
+	assert.Equal(t, "2.0 MiB/s", ProgressUnitBytes.FormatRate(2*1024*1024))
+	assert.Equal(t, "2.5/s", ProgressUnitNone.FormatRate(2.5))
+}
+
+func TestOutputConfig_getLogTailText(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	cfg.DefaultTextWidth = 24
+	cfg.LogTailLines = 2
+
+	msg := &Message{Status: MessageStatusStarted, Log: []string{"first", "second", "third line\tthat is too long"}}
+	assert.Equal(t, []string{
+		"  second                \n",
+		"  third line that is to…\n",
+	}, cfg.getLogTailText(msg, 0))
+	assert.Equal(t, []string{
+		"    second              \n",
+		"    third line that is …\n",
+	}, cfg.getLogTailText(msg, 1))
+
+	cfg.LogTailLines = 0
+	assert.Empty(t, cfg.getLogTailText(msg, 0))
+
+	cfg.LogTailLines = 2
+	msg.Status = MessageStatusSuccess
+	assert.Empty(t, cfg.getLogTailText(msg, 0))
+}
+
+func TestOutputConfig_formatDetails_Log(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+
+	msg := &Message{Status: MessageStatusError, Details: "exit status 1", Log: []string{"building", "build failed"}}
+	assert.Equal(t, "\n  exit status 1\n  building\n  build failed", cfg.formatDetails(msg, ""))
+
+	msg.Status = MessageStatusSuccess
+	assert.Equal(t, "\n  exit status 1", cfg.formatDetails(msg, ""))
+}
+
+func TestMessageRenderer_listInProgressTree_Pending(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	cfg.DefaultTextWidth = 16
+
+	ms := NewMessageStore()
+	for _, update := range []Update{
+		{Key: "pending", Message: "Test pending", Status: MessageStatusPending},
+		{Key: "started", Message: "Test started", Status: MessageStatusStarted},
+		{Key: "child", ParentKey: "started", Message: "Test child", Status: MessageStatusPending},
+	} {
+		assert.NoError(t, ms.Push(update))
+	}
+
+	r := NewMessageRenderer(cfg)
+	var rows []string
+	for _, item := range r.listInProgressTree(ms) {
+		rows = append(rows, cfg.getMessageText(item.msg, r.renderState, item.depth, false))
+	}
+	assert.Equal(t, []string{
+		"> Test started  \n",
+		"  # Test child  \n",
+		"# Test pending  \n",
+	}, rows)
+}
+
+func TestMessageRenderer_listInProgressTree_DependsOn(t *testing.T) {
+	t.Parallel()
+	cfg := GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	cfg.DefaultTextWidth = 18
+
+	ms := NewMessageStore()
+	for _, update := range []Update{
+		{Key: "network", Message: "Create network", Status: MessageStatusStarted},
+		{Key: "server", Message: "Create server", Status: MessageStatusStarted, DependsOn: []string{"network"}},
+	} {
+		assert.NoError(t, ms.Push(update))
+	}
+
+	r := NewMessageRenderer(cfg)
+	render := func() []string {
+		var rows []string
+		for _, item := range r.listInProgressTree(ms) {
+			rows = append(rows, cfg.getMessageText(item.msg, r.renderState, item.depth, false))
+		}
+		return rows
+	}
+
+	// Server waits for network to finish.
+	assert.Equal(t, []string{
+		"> Create network  \n",
+		"# Create server   \n",
+	}, render())
+
+	assert.NoError(t, ms.Push(Update{Key: "network", Status: MessageStatusSuccess}))
+	assert.Equal(t, []string{
+		"> Create server   \n",
+	}, render())
+}
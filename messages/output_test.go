@@ -3,6 +3,7 @@ package messages_test
 import (
 	"bytes"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -160,3 +161,144 @@ func TestMessageRenderer_RenderMessageStore(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageRenderer_RenderMessageStore_Children(t *testing.T) {
+	t.Parallel()
+	cfg := messages.GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	renderer := messages.NewMessageRenderer(cfg)
+	store := messages.NewMessageStore()
+
+	for _, update := range []messages.Update{
+		{Key: "parent", Message: "Test parent", Status: messages.MessageStatusStarted},
+		{Key: "child-1", ParentKey: "parent", Message: "Test child 1", Status: messages.MessageStatusStarted},
+		{Key: "child-2", ParentKey: "parent", Message: "Test child 2", Status: messages.MessageStatusStarted},
+		{Key: "grandchild", ParentKey: "child-2", Message: "Test grandchild", Status: messages.MessageStatusStarted},
+		{Key: "child-1", Status: messages.MessageStatusSuccess},
+		{Key: "grandchild", Status: messages.MessageStatusWarning, Details: "Warning: Details are indented with the message"},
+		{Key: "child-2", StatusFromChildren: true},
+		{Key: "parent", StatusFromChildren: true},
+		{Key: "other", Message: "Test message without parent", Status: messages.MessageStatusSuccess},
+	} {
+		time.Sleep(time.Microsecond * 25) // Ensure time difference on Windows
+		err := store.Push(update)
+		assert.NoError(t, err)
+		renderer.RenderMessageStore(store)
+	}
+
+	output := buf.String()
+	cupaloy.SnapshotT(t, output)
+}
+
+func TestMessageRenderer_RenderMessageStore_NonInteractiveProgress(t *testing.T) {
+	t.Parallel()
+	cfg := messages.GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	renderer := messages.NewMessageRenderer(cfg)
+	store := messages.NewMessageStore()
+
+	err := store.Push(messages.Update{Key: "test", Message: "Test progress", Status: messages.MessageStatusStarted, Total: 100})
+	assert.NoError(t, err)
+	renderer.RenderMessageStore(store)
+
+	for _, current := range []int64{10, 20, 30, 60, 70, 100} {
+		err = store.Push(messages.Update{Key: "test", Current: current})
+		assert.NoError(t, err)
+		renderer.RenderMessageStore(store)
+	}
+
+	err = store.Push(messages.Update{Key: "test", Status: messages.MessageStatusSuccess})
+	assert.NoError(t, err)
+	renderer.RenderMessageStore(store)
+
+	expected := "" +
+		"> Test progress                                                                                  0 %\n" +
+		"> Test progress                                                                                 30 %\n" +
+		"> Test progress                                                                                 60 %\n" +
+		"> Test progress                                                                                100 %\n" +
+		"✓ Test progress                                                                                     \n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestMessageRenderer_RenderMessageStore_Attempts(t *testing.T) {
+	t.Parallel()
+	cfg := messages.GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	renderer := messages.NewMessageRenderer(cfg)
+	store := messages.NewMessageStore()
+
+	for _, update := range []messages.Update{
+		{Key: "test", Message: "Test retry", Status: messages.MessageStatusStarted, MaxAttempts: 3},
+		{Key: "test", FailedAttempt: "Error: timeout"},
+		{Key: "test", FailedAttempt: "Error: service unavailable"},
+		{Key: "test", Status: messages.MessageStatusSuccess, Details: "Succeeded on third attempt"},
+	} {
+		err := store.Push(update)
+		assert.NoError(t, err)
+		renderer.RenderMessageStore(store)
+	}
+
+	output := buf.String()
+	cupaloy.SnapshotT(t, output)
+}
+
+func TestMessageRenderer_RenderMessageStore_Stalled(t *testing.T) {
+	t.Parallel()
+	cfg := messages.GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	cfg.StallThreshold = time.Minute
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	renderer := messages.NewMessageRenderer(cfg)
+	store := messages.NewMessageStore()
+
+	err := store.Add(messages.Message{
+		Message: "Test stalled",
+		Status:  messages.MessageStatusStarted,
+		Started: time.Now().Add(-time.Minute * 3),
+		Updated: time.Now().Add(-time.Minute * 2),
+	})
+	assert.NoError(t, err)
+	renderer.RenderMessageStore(store)
+	renderer.RenderMessageStore(store)
+
+	assert.Equal(t, "~ Test stalled (no updates for 2m)"+strings.Repeat(" ", 61)+"180 s\n", buf.String())
+}
+
+func TestOutputConfig_GetSummaryText(t *testing.T) {
+	t.Parallel()
+	cfg := messages.GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	now := time.Now()
+
+	assert.Equal(t, "", cfg.GetSummaryText(messages.Summary{}))
+
+	slow := &messages.Message{Message: "Test slow", Status: messages.MessageStatusSuccess, Started: now, Finished: now.Add(time.Minute)}
+	failed := &messages.Message{Message: "Test error", Status: messages.MessageStatusError, Details: "test error", Started: now, Finished: now.Add(time.Second * 5)}
+	warning := &messages.Message{Message: "Test warning", Status: messages.MessageStatusWarning}
+	cancelled := &messages.Message{Message: "Test cancelled", Status: messages.MessageStatusCancelled, Details: "context canceled"}
+	summary := messages.Summary{
+		StatusCounts: map[messages.MessageStatus]int{
+			messages.MessageStatusSuccess:   10,
+			messages.MessageStatusWarning:   1,
+			messages.MessageStatusError:     1,
+			messages.MessageStatusCancelled: 1,
+		},
+		Elapsed:   time.Second * 90,
+		Slowest:   []*messages.Message{slow, failed},
+		Errors:    []*messages.Message{failed},
+		Cancelled: []*messages.Message{cancelled},
+		Warnings:  []*messages.Message{warning},
+	}
+	cupaloy.SnapshotT(t, cfg.GetSummaryText(summary))
+}
@@ -0,0 +1,46 @@
+package messages
+
+import "fmt"
+
+// ProgressUnit defines how numeric progress and its rate are formatted.
+type ProgressUnit string
+
+const (
+	ProgressUnitNone  ProgressUnit = ""
+	ProgressUnitBytes ProgressUnit = "bytes"
+)
+
+func getBinaryPrefixes() []string {
+	return []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+}
+
+func formatBytes(value float64) string {
+	if value < 1024 {
+		return fmt.Sprintf("%d B", int64(value))
+	}
+
+	prefix := ""
+	for _, prefix = range getBinaryPrefixes() {
+		value /= 1024
+		if value < 1024 {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, prefix)
+}
+
+// Format formats value of numeric progress in the unit, e.g. 1536 bytes as `1.5 KiB`.
+func (u ProgressUnit) Format(value float64) string {
+	if u == ProgressUnitBytes {
+		return formatBytes(value)
+	}
+	return fmt.Sprintf("%d", int64(value))
+}
+
+// FormatRate formats rate of numeric progress in the unit per second, e.g. 1536 bytes per second as `1.5 KiB/s`.
+func (u ProgressUnit) FormatRate(rate float64) string {
+	if u == ProgressUnitBytes {
+		return formatBytes(rate) + "/s"
+	}
+	return fmt.Sprintf("%.1f/s", rate)
+}
@@ -0,0 +1,112 @@
+package messages
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// ExitCodeSuccess is the exit code for runs where all messages finished without errors or warnings.
+	ExitCodeSuccess = 0
+	// ExitCodeError is the exit code for runs where some of the messages finished with error, cancelled, or unknown status.
+	ExitCodeError = 1
+	// ExitCodeWarning is the exit code for runs where none of the messages failed, but some of them finished with warning status.
+	ExitCodeWarning = 2
+)
+
+// Summary is an overview of the messages in MessageStore.
+type Summary struct {
+	// StatusCounts contains the number of messages per status.
+	StatusCounts map[MessageStatus]int
+	// Elapsed is the duration from the creation of the first message to the finishing of the last message. If some of the messages have not finished yet, current time is used as the end time.
+	Elapsed time.Duration
+	// Slowest lists the finished messages that took the longest time from start to finish, the slowest first.
+	Slowest []*Message
+	// Errors lists the messages with error status in the order they finished.
+	Errors []*Message
+	// Cancelled lists the messages with cancelled status in the order they finished.
+	Cancelled []*Message
+	// Warnings lists the messages with warning status in the order they finished.
+	Warnings []*Message
+}
+
+// getSummaryStatuses returns the statuses in the order they are listed in the rendered summary.
+func getSummaryStatuses() []MessageStatus {
+	return []MessageStatus{
+		MessageStatusSuccess,
+		MessageStatusWarning,
+		MessageStatusError,
+		MessageStatusSkipped,
+		MessageStatusCancelled,
+		MessageStatusUnknown,
+		MessageStatusStarted,
+		MessageStatusPending,
+	}
+}
+
+func getDuration(msg *Message) time.Duration {
+	if msg.Started.IsZero() || msg.Finished.IsZero() {
+		return 0
+	}
+	return msg.Finished.Sub(msg.Started)
+}
+
+// Summary computes summary of the messages in MessageStore. At most slowestCount messages are included in the list of slowest messages.
+func (ms *MessageStore) Summary(slowestCount int) Summary {
+	summary := Summary{StatusCounts: make(map[MessageStatus]int)}
+
+	var first, last time.Time
+	for _, msg := range append(ms.ListFinished(), ms.ListInProgress()...) {
+		summary.StatusCounts[msg.Status]++
+
+		end := msg.Finished
+		if end.IsZero() {
+			end = time.Now()
+		}
+		if first.IsZero() || msg.Created.Before(first) {
+			first = msg.Created
+		}
+		if end.After(last) {
+			last = end
+		}
+
+		switch msg.Status {
+		case MessageStatusError:
+			summary.Errors = append(summary.Errors, msg)
+		case MessageStatusCancelled:
+			summary.Cancelled = append(summary.Cancelled, msg)
+		case MessageStatusWarning:
+			summary.Warnings = append(summary.Warnings, msg)
+		}
+
+		if getDuration(msg) > 0 {
+			summary.Slowest = append(summary.Slowest, msg)
+		}
+	}
+	if !first.IsZero() {
+		summary.Elapsed = last.Sub(first)
+	}
+
+	sort.SliceStable(summary.Slowest, func(i, j int) bool {
+		return getDuration(summary.Slowest[i]) > getDuration(summary.Slowest[j])
+	})
+	if len(summary.Slowest) > slowestCount {
+		summary.Slowest = summary.Slowest[:max(slowestCount, 0)]
+	}
+
+	return summary
+}
+
+// ExitCode maps the summary into a conventional process exit code: ExitCodeError, if any of the messages finished with error, cancelled, or unknown status, ExitCodeWarning, if any of the messages finished with warning status, and ExitCodeSuccess otherwise.
+func (s Summary) ExitCode() int {
+	for status, count := range s.StatusCounts {
+		if status.isFailure() && count > 0 {
+			return ExitCodeError
+		}
+	}
+
+	if s.StatusCounts[MessageStatusWarning] > 0 {
+		return ExitCodeWarning
+	}
+	return ExitCodeSuccess
+}
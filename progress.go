@@ -1,6 +1,7 @@
 package progress
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,13 +17,26 @@ func GetDefaultOutputConfig() *OutputConfig {
 }
 
 type Progress struct {
-	store      *messages.MessageStore
-	renderer   *messages.MessageRenderer
-	updateChan chan messages.Update
-	errorChan  chan error
-	renderChan chan bool
-	stopChan   chan bool
-	doneChan   chan bool
+	ctx             context.Context //nolint:containedctx // Context given to StartContext is passed to functions executed with Run.
+	config          messages.OutputConfig
+	store           *messages.MessageStore
+	renderer        Renderer
+	updateChan      chan messages.Update
+	outputChan      chan string
+	summaryChan     chan chan messages.Summary
+	subscribeChan   chan *subscription
+	unsubscribeChan chan *subscription
+	subscriptions   []*subscription
+	errorChan       chan error
+	renderWaitChan  chan chan bool
+	timeoutWaitChan chan timeoutWaiter
+	timeoutWaiters  map[string][]chan bool
+	stdio           *stdioCapture
+	retargetChan    chan retarget
+	recorder        *recorder
+	stopChan        chan bool
+	stoppedChan     chan bool
+	doneChan        chan bool
 }
 
 // NewProgress creates new Progress instance. Use nil config for default output configuration.
@@ -31,52 +45,179 @@ func NewProgress(config *OutputConfig) *Progress {
 		config = GetDefaultOutputConfig()
 	}
 
+	return NewProgressWithRenderer(config, newRenderer(messages.OutputConfig(*config)))
+}
+
+// NewProgressWithRenderer creates new Progress instance that renders the progress log with given renderer. Use nil config for default output configuration.
+func NewProgressWithRenderer(config *OutputConfig, renderer Renderer) *Progress {
+	if config == nil {
+		config = GetDefaultOutputConfig()
+	}
+
+	store := messages.NewMessageStore()
+	if tracker, ok := renderer.(ChangeTracker); ok && tracker.TrackChanges() {
+		store.TrackChanges()
+	}
+
 	return &Progress{
-		store:     messages.NewMessageStore(),
-		renderer:  messages.NewMessageRenderer(messages.OutputConfig(*config)),
-		errorChan: make(chan error),
-		doneChan:  make(chan bool),
+		config:         messages.OutputConfig(*config),
+		store:          store,
+		renderer:       renderer,
+		errorChan:      make(chan error),
+		stoppedChan:    make(chan bool),
+		doneChan:       make(chan bool),
+		timeoutWaiters: make(map[string][]chan bool),
+		stdio:          &stdioCapture{},
 	}
 }
 
-func (p *Progress) onRender() {
-	if p.renderChan != nil {
-		p.renderChan <- true
-	}
+func (p *Progress) render(final bool) {
+	p.renderer.Render(p.store, final)
 }
 
-func (p *Progress) run() {
+// runLoop is the state of the goroutine started by Start. It is kept outside of Progress as Progress is copied when methods with value receiver are called.
+type runLoop struct {
+	p             *Progress
+	ctx           context.Context //nolint:containedctx // Context is checked for cancellation when handling events.
+	cancelCause   error
+	renderWaiters []chan bool
+	subscriptions []*subscription
+}
+
+func (p *Progress) run(ctx context.Context) {
 	ticker := time.NewTicker(time.Millisecond * 95)
 	defer ticker.Stop()
+
+	l := &runLoop{p: p, ctx: ctx, subscriptions: p.subscriptions}
 	for {
 		select {
+		case <-ctx.Done():
+			l.isCancelled()
 		case <-p.stopChan:
-			p.store.Close()
-			p.renderer.RenderMessageStore(p.store)
-			p.onRender()
-			p.doneChan <- true
+			l.stop()
 			return
+		case s := <-p.subscribeChan:
+			l.subscriptions = append(l.subscriptions, s)
+			s.unsubscribeStore = p.store.Subscribe(s.push)
+		case s := <-p.unsubscribeChan:
+			l.subscriptions = removeSubscription(l.subscriptions, s)
+		case summary := <-p.summaryChan:
+			summary <- p.store.Summary(p.config.SummarySlowestCount)
+		case waiter := <-p.renderWaitChan:
+			l.addRenderWaiter(waiter)
+		case waiter := <-p.timeoutWaitChan:
+			p.addTimeoutWaiter(waiter)
+		case r := <-p.retargetChan:
+			p.handleRetarget(r)
+			p.errorChan <- nil
 		case update := <-p.updateChan:
-			p.errorChan <- p.store.Push(update)
+			p.errorChan <- l.push(update)
+		case output := <-p.outputChan:
+			p.errorChan <- l.appendOutput(output)
 		case <-ticker.C:
-			p.renderer.RenderMessageStore(p.store)
-			p.onRender()
+			if !l.isCancelled() {
+				p.expireOverdue()
+				l.render(false)
+			}
+		}
+	}
+}
+
+// render renders the progress log and releases the goroutines waiting for the render.
+func (l *runLoop) render(final bool) {
+	l.p.render(final)
+	for _, waiter := range l.renderWaiters {
+		close(waiter)
+	}
+	l.renderWaiters = nil
+}
+
+// isCancelled cancels the progress log, if the context given to StartContext has been cancelled. Cancellation is checked before handling other events to ensure that the final state is rendered only once, even if stop and cancellation happen at the same time.
+func (l *runLoop) isCancelled() bool {
+	if l.cancelCause == nil && l.ctx.Err() != nil {
+		l.cancelCause = context.Cause(l.ctx)
+		l.p.cancel(l.cancelCause)
+		l.render(false)
+	}
+	return l.cancelCause != nil
+}
+
+func (l *runLoop) stop() {
+	if !l.isCancelled() {
+		l.p.store.Close()
+	}
+	l.render(true)
+	closeSubscriptions(l.subscriptions)
+	close(l.p.stoppedChan)
+	l.p.doneChan <- true
+}
+
+func (l *runLoop) addRenderWaiter(waiter chan bool) {
+	if l.isCancelled() {
+		// Final state has already been rendered.
+		close(waiter)
+		return
+	}
+	l.renderWaiters = append(l.renderWaiters, waiter)
+}
+
+func (l *runLoop) push(update messages.Update) error {
+	if l.isCancelled() {
+		return fmt.Errorf("can not push updates into cancelled progress log: %w", l.cancelCause)
+	}
+
+	l.p.recorder.recordUpdate(update)
+	err := l.p.store.Push(update)
+	l.p.removeTimeoutWaiters(update)
+	return err
+}
+
+func (l *runLoop) appendOutput(output string) error {
+	if l.isCancelled() {
+		return fmt.Errorf("can not write into cancelled progress log: %w", l.cancelCause)
+	}
+
+	l.p.recorder.record(recordedEvent{Output: output})
+	l.p.store.AppendOutput(output)
+	return nil
+}
+
+// cancel finishes in-progress messages because of cancellation and records the changes.
+func (p *Progress) cancel(cause error) {
+	inProgress := p.store.ListInProgress()
+	p.store.Cancel(cause)
+	for _, msg := range inProgress {
+		if msg.Status.IsFinished() {
+			p.recorder.record(recordedEvent{Cancelled: recordChange(msg)})
 		}
 	}
 }
 
 // Start the progress logging in a new goroutine. Panics if called more than once.
 func (p *Progress) Start() {
+	p.StartContext(context.Background())
+}
+
+// StartContext starts the progress logging in a new goroutine that is tied to given context. When the context is cancelled, pending messages are marked skipped, started messages are marked cancelled with the context's cause as details, and the final state is rendered. Stop must still be called to terminate the goroutine. Panics if called more than once.
+func (p *Progress) StartContext(ctx context.Context) {
 	if p.stopChan != nil {
 		panic("can not start progress log more than once")
 	}
 
+	p.ctx = ctx
 	p.stopChan = make(chan bool)
 	p.updateChan = make(chan messages.Update)
-	go p.run()
+	p.outputChan = make(chan string)
+	p.summaryChan = make(chan chan messages.Summary)
+	p.subscribeChan = make(chan *subscription)
+	p.unsubscribeChan = make(chan *subscription)
+	p.renderWaitChan = make(chan chan bool)
+	p.timeoutWaitChan = make(chan timeoutWaiter)
+	p.retargetChan = make(chan retarget)
+	go p.run(ctx)
 }
 
-// Push updates to the progress log. Errors if called before Start or if called with an invalid update. Panics if called after Stop.
+// Push updates to the progress log. Errors if called before Start, after the context given to StartContext has been cancelled, or if called with an invalid update. Panics if called after Stop.
 func (p Progress) Push(update messages.Update) error {
 	if p.updateChan == nil {
 		return fmt.Errorf("can not push updates into progress log that has not been started")
@@ -86,27 +227,65 @@ func (p Progress) Push(update messages.Update) error {
 	return <-p.errorChan
 }
 
-// Wait until the messages are next rendered. Supports only one call at a time. I.e., panics if called more than once in parallel.
+// AppendLog appends line to the log of the message identified by key. In TTY terminals, the latest log lines are rendered under the started message. If the message finishes with error status, the full log is outputted with its details.
+func (p Progress) AppendLog(key, line string) error {
+	return p.Push(messages.Update{Key: key, Log: line})
+}
+
+// Wait until the messages are next rendered. Can be called from multiple goroutines in parallel.
 func (p *Progress) WaitForRender() {
-	if p.renderChan != nil {
-		panic("can not wait for render more than once")
+	waiter := make(chan bool)
+	p.renderWaitChan <- waiter
+	<-waiter
+}
+
+// Finish stops the progress log, like Stop, and returns the errors of messages that finished with error, cancelled, or unknown status joined with errors.Join. Each of the joined errors is a *messages.MessageError that unwraps to the error given in Err field of the update that finished the message. Returns nil, if no messages failed. Use Summary().ExitCode() to map the final state into a process exit code. Panics if called before start or more than once.
+func (p *Progress) Finish() error {
+	p.Stop()
+	return p.store.Err()
+}
+
+// Summary returns summary of the messages in the progress log. The list of slowest messages is limited by SummarySlowestCount output configuration option. Can be called before Start, while the progress log is running, and after Stop.
+func (p *Progress) Summary() messages.Summary {
+	select {
+	case <-p.stoppedChan:
+		return p.store.Summary(p.config.SummarySlowestCount)
+	default:
+	}
+
+	if p.summaryChan == nil {
+		return p.store.Summary(p.config.SummarySlowestCount)
 	}
 
-	p.renderChan = make(chan bool)
-	<-p.renderChan
-	p.renderChan = nil
+	summary := make(chan messages.Summary)
+	select {
+	case p.summaryChan <- summary:
+		return <-summary
+	case <-p.stoppedChan:
+		return p.store.Summary(p.config.SummarySlowestCount)
+	}
 }
 
-// Stop the goroutine handling progress logging and render the final progress state. Panics if called before start or more than once.
+// Stop the goroutine handling progress logging and render the final progress state. If stdio has been captured with CaptureStdio, the original files are restored. Panics if called before start or more than once.
 func (p Progress) Stop() {
 	if p.stopChan == nil {
 		panic("can not stop progress log that has not been started")
 	}
 
+	// Output captured lines before rendering the final state.
+	p.restoreStdio()
+
 	p.stopChan <- true
 	// Block until stop is handled
 	<-p.doneChan
 
 	close(p.stopChan)
 	close(p.updateChan)
+	close(p.outputChan)
+	close(p.summaryChan)
+	close(p.subscribeChan)
+	close(p.unsubscribeChan)
+	close(p.renderWaitChan)
+	close(p.timeoutWaitChan)
+	close(p.retargetChan)
 }
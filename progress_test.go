@@ -2,9 +2,12 @@ package progress_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -146,6 +149,36 @@ func TestProgress_ClosesInProgressMessagesOnStop(t *testing.T) {
 	assert.Equal(t, expected, output)
 }
 
+func TestProgress_StartContext_CancelsInProgressMessages(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	taskLog := progress.NewProgress(cfg)
+	taskLog.StartContext(ctx)
+
+	err := taskLog.Push(messages.Update{Message: "Test pending", Status: messages.MessageStatusPending})
+	assert.NoError(t, err)
+	time.Sleep(time.Microsecond * 25) // Ensure time difference on Windows
+	err = taskLog.Push(messages.Update{Message: "Test started", Status: messages.MessageStatusStarted})
+	assert.NoError(t, err)
+
+	taskLog.WaitForRender()
+	cancel(errors.New("interrupted"))
+
+	err = taskLog.Push(messages.Update{Message: "Test after cancel", Status: messages.MessageStatusStarted})
+	assert.EqualError(t, err, "can not push updates into cancelled progress log: interrupted")
+
+	taskLog.Stop()
+
+	output := buf.String()
+
+	expected := removeColorsOnWindows("\x1b[34m> \x1b[0mTest started                                                                                      \n\x1b[35m- \x1b[0mTest pending                                                                                      \n\x1b[91m⊘ \x1b[0mTest started                                                                                      \n  \x1b[90minterrupted\x1b[0m\n")
+	assert.Equal(t, expected, output)
+}
+
 func TestProgress_WaitForRender(t *testing.T) {
 	t.Parallel()
 
@@ -186,3 +219,81 @@ func TestProgress_WaitForRender(t *testing.T) {
 		})
 	}
 }
+
+func TestProgress_Summary(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+	cfg.ShowSummary = true
+
+	taskLog := progress.NewProgress(cfg)
+	assert.Len(t, taskLog.Summary().StatusCounts, 0)
+	taskLog.Start()
+
+	assert.NoError(t, taskLog.Push(messages.Update{Message: "Test success", Status: messages.MessageStatusSuccess}))
+	assert.NoError(t, taskLog.Push(messages.Update{Message: "Test error", Status: messages.MessageStatusError, Details: "test error"}))
+	assert.NoError(t, taskLog.Push(messages.Update{Message: "Test started", Status: messages.MessageStatusStarted}))
+	assert.Equal(t, 1, taskLog.Summary().StatusCounts[messages.MessageStatusStarted])
+
+	taskLog.Stop()
+
+	summary := taskLog.Summary()
+	assert.Equal(t, 0, summary.StatusCounts[messages.MessageStatusStarted])
+	assert.Equal(t, 1, summary.StatusCounts[messages.MessageStatusUnknown])
+	assert.Len(t, summary.Errors, 1)
+	assert.Contains(t, buf.String(), "\nSummary: 1 success, 1 error, 1 unknown in 0s\nErrors:\n  ✗ Test error")
+	assert.Contains(t, buf.String(), "\n    test error\n")
+}
+
+func TestProgress_Finish(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+	assert.NoError(t, taskLog.Push(messages.Update{Message: "Test warning", Status: messages.MessageStatusWarning}))
+	assert.NoError(t, taskLog.Finish())
+	assert.Equal(t, messages.ExitCodeWarning, taskLog.Summary().ExitCode())
+
+	errTest := errors.New("test error")
+	taskLog = progress.NewProgress(cfg)
+	taskLog.Start()
+	assert.NoError(t, taskLog.Task("error", "Test error").Fail(errTest))
+	err := taskLog.Finish()
+	assert.ErrorIs(t, err, errTest)
+	assert.EqualError(t, err, "Test error: test error")
+	assert.Equal(t, messages.ExitCodeError, taskLog.Summary().ExitCode())
+
+	// Messages that never finished are not successful.
+	taskLog = progress.NewProgress(cfg)
+	taskLog.Start()
+	assert.NoError(t, taskLog.Task("started", "Test started").Start())
+	assert.EqualError(t, taskLog.Finish(), "Test started: unknown")
+	assert.Equal(t, messages.ExitCodeError, taskLog.Summary().ExitCode())
+}
+
+func TestProgress_OutputFormatJSON(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.OutputFormat = messages.OutputFormatJSON
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	fmt.Fprintln(taskLog.Writer(), "Test output")
+	assert.NoError(t, taskLog.Push(messages.Update{Key: "test", Message: "Test message", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, taskLog.Push(messages.Update{Key: "test", Status: messages.MessageStatusSuccess}))
+
+	taskLog.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, `{"output":"Test output"}`, lines[0])
+	assert.Contains(t, lines[1], `"key":"test","message":"Test message","status":"started"`)
+	assert.Contains(t, lines[2], `"key":"test","message":"Test message","status":"success"`)
+}
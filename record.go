@@ -0,0 +1,118 @@
+package progress
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// recordedEvent is a line in a recording. Each line contains either an update pushed to the progress log, output written to it, or a change the progress log made on its own: a message that expired because of its timeout or a message that was finished because the context given to StartContext was cancelled. Changes are recorded as updates that reproduce them.
+type recordedEvent struct {
+	Time      time.Time        `json:"time"`
+	Update    *messages.Update `json:"update,omitempty"`
+	Expired   *messages.Update `json:"expired,omitempty"`
+	Cancelled *messages.Update `json:"cancelled,omitempty"`
+	Output    string           `json:"output,omitempty"`
+}
+
+type recorder struct {
+	encoder *json.Encoder
+	err     error
+}
+
+func (r *recorder) record(event recordedEvent) {
+	if r == nil || r.err != nil {
+		return
+	}
+
+	event.Time = time.Now()
+	r.err = r.encoder.Encode(event)
+}
+
+func (r *recorder) recordUpdate(update messages.Update) {
+	// Err is not encoded, so include it as details to reproduce the same output.
+	if update.Err != nil && update.Details == "" {
+		update.Details = update.Err.Error()
+	}
+	r.record(recordedEvent{Update: &update})
+}
+
+// recordChange returns an update that reproduces the current state of msg after the progress log has changed it.
+func recordChange(msg *messages.Message) *messages.Update {
+	return &messages.Update{
+		Key:     msg.Key,
+		Status:  msg.Status,
+		Details: msg.Details,
+	}
+}
+
+// Record writes every update pushed to the progress log, all output written to it, e.g. with Writer, and messages finished by timeouts or cancellation with timestamps to w as JSON Lines. Use Replay to feed the recording back into a progress log. Recording stops at the first error returned by w. Returns error, if called after Start.
+func (p *Progress) Record(w io.Writer) error {
+	if p.stopChan != nil {
+		return fmt.Errorf("can not record progress log that has already been started")
+	}
+
+	p.recorder = &recorder{encoder: json.NewEncoder(w)}
+	return nil
+}
+
+// Replay pushes the updates and output of a recording created with Record into started p. Speed 1 replays the recording at original pace, 2 twice as fast, and 0 without delays. Returns error, if reading the recording fails or the context given to StartContext is cancelled.
+func Replay(p *Progress, r io.Reader, speed float64) error {
+	ctx := p.context()
+
+	var previous time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var event recordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("failed to parse line %d of recording: %w", line, err)
+		}
+
+		if speed > 0 && !previous.IsZero() {
+			if err := sleep(ctx, time.Duration(float64(event.Time.Sub(previous))/speed)); err != nil {
+				return err
+			}
+		}
+		previous = event.Time
+
+		// Errors are not included in the recording, but the error of timed out messages is known.
+		if event.Expired != nil {
+			event.Expired.Err = messages.ErrTimedOut
+		}
+		for _, update := range []*messages.Update{event.Update, event.Expired, event.Cancelled} {
+			if update != nil {
+				update.Timeout = 0
+				_ = p.Push(*update)
+			}
+		}
+		if event.Output != "" {
+			_, _ = p.Writer().Write([]byte(event.Output))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read recording: %w", err)
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
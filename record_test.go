@@ -0,0 +1,104 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/UpCloudLtd/progress/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	original := bytes.NewBuffer(nil)
+	cfg.Target = original
+
+	recording := bytes.NewBuffer(nil)
+	taskLog := progress.NewProgress(cfg)
+	assert.NoError(t, taskLog.Record(recording))
+	taskLog.Start()
+	assert.EqualError(t, taskLog.Record(recording), "can not record progress log that has already been started")
+
+	task := taskLog.Task("test", "Test task")
+	assert.NoError(t, task.Start())
+	fmt.Fprintln(taskLog.Writer(), "Test output")
+	assert.NoError(t, task.Fail(errors.New("test error")))
+	assert.Error(t, taskLog.Push(messages.Update{Key: "invalid"}))
+	assert.NoError(t, taskLog.Push(messages.Update{Message: "Test success", Status: messages.MessageStatusSuccess}))
+
+	taskLog.Stop()
+
+	assert.Equal(t, 5, strings.Count(recording.String(), "\n"))
+	assert.Contains(t, recording.String(), `"update":{"key":"test","status":"error","details":"test error"}`)
+
+	cfg.Target = bytes.NewBuffer(nil)
+	replayed := progress.NewProgress(cfg)
+	replayed.Start()
+	assert.NoError(t, progress.Replay(replayed, recording, 0))
+	replayed.Stop()
+
+	assert.Equal(t, original.String(), cfg.Target.(*bytes.Buffer).String())
+}
+
+func TestRecordAndReplay_TimeoutAndCancel(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.DisableColors = true
+	original := bytes.NewBuffer(nil)
+	cfg.Target = original
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recording := bytes.NewBuffer(nil)
+	taskLog := progress.NewProgress(cfg)
+	assert.NoError(t, taskLog.Record(recording))
+	taskLog.StartContext(ctx)
+
+	timed := taskLog.Task("timed", "Timed task")
+	timed.SetTimeout(time.Millisecond * 150)
+	assert.NoError(t, timed.Start())
+	<-timed.TimedOut()
+	assert.Error(t, timed.Succeed())
+
+	assert.NoError(t, taskLog.Task("cancelled", "Cancelled task").Start())
+	cancel()
+	taskLog.Stop()
+
+	assert.Contains(t, recording.String(), `"expired":{"key":"timed","status":"error","details":"Timed out after 0.15 s"}`)
+	assert.Contains(t, recording.String(), `"cancelled":{"key":"cancelled","status":"cancelled","details":"context canceled"}`)
+
+	for _, speed := range []float64{0, 1} {
+		cfg.Target = bytes.NewBuffer(nil)
+		replayed := progress.NewProgress(cfg)
+		replayed.Start()
+		assert.NoError(t, progress.Replay(replayed, bytes.NewReader(recording.Bytes()), speed))
+		assert.ErrorIs(t, replayed.Finish(), progress.ErrTimedOut)
+
+		// Started state of the timed message is rendered only if the progress log is rendered before the message times out, so compare the finished messages.
+		output := cfg.Target.(*bytes.Buffer).String()
+		assert.Contains(t, output, "✗ Timed task")
+		assert.Equal(t, finishedOutput(original.String()), finishedOutput(output))
+	}
+}
+
+func finishedOutput(output string) string {
+	if i := strings.Index(output, "✗ "); i >= 0 {
+		return output[i:]
+	}
+	return output
+}
+
+func TestReplay_InvalidRecording(t *testing.T) {
+	t.Parallel()
+	taskLog := progress.NewProgress(nil)
+
+	err := progress.Replay(taskLog, strings.NewReader("{}\nnot json\n"), 1)
+	assert.ErrorContains(t, err, "failed to parse line 2 of recording")
+}
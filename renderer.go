@@ -0,0 +1,30 @@
+package progress
+
+import (
+	"io"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// Renderer renders the state of the progress log. Render is called periodically, when the progress log is cancelled, and when the progress log is stopped, always from the goroutine started by Start. When stopping, final is true and Render is not called again afterwards. The message store must not be accessed outside of Render.
+type Renderer interface {
+	Render(store *messages.MessageStore, final bool)
+}
+
+// ChangeTracker can be implemented by a Renderer that renders the changes in the state of messages instead of the current state. If TrackChanges returns true, change tracking is enabled in the message store before any updates are pushed to it. The Renderer must then consume the changes with messages.MessageStore.TakeChanges.
+type ChangeTracker interface {
+	TrackChanges() bool
+}
+
+// TargetSwitcher can be implemented by a Renderer that writes into a file. CaptureStdio uses it to keep the Renderer writing into the terminal when the standard output or error of the process, that the Renderer writes into, is redirected. SetTarget is called from the goroutine started by Start.
+type TargetSwitcher interface {
+	Target() io.Writer
+	SetTarget(target io.Writer)
+}
+
+func newRenderer(config messages.OutputConfig) Renderer {
+	if config.OutputFormat == messages.OutputFormatJSON {
+		return messages.NewJSONRenderer(config)
+	}
+	return messages.NewMessageRenderer(config)
+}
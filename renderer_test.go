@@ -0,0 +1,47 @@
+package progress_test
+
+import (
+	"testing"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/UpCloudLtd/progress/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRenderer struct {
+	changes     []messages.Message
+	finalCount  int
+	renderCount int
+}
+
+func (r *testRenderer) Render(store *messages.MessageStore, final bool) {
+	r.changes = append(r.changes, store.TakeChanges()...)
+	r.renderCount++
+	if final {
+		r.finalCount++
+	}
+}
+
+func (r *testRenderer) TrackChanges() bool {
+	return true
+}
+
+func TestNewProgressWithRenderer(t *testing.T) {
+	t.Parallel()
+	renderer := &testRenderer{}
+
+	taskLog := progress.NewProgressWithRenderer(nil, renderer)
+	taskLog.Start()
+
+	assert.NoError(t, taskLog.Push(messages.Update{Key: "test", Message: "Test message", Status: messages.MessageStatusStarted}))
+	taskLog.WaitForRender()
+	assert.NoError(t, taskLog.Push(messages.Update{Key: "test", Status: messages.MessageStatusSuccess}))
+
+	taskLog.Stop()
+
+	assert.GreaterOrEqual(t, renderer.renderCount, 2)
+	assert.Equal(t, 1, renderer.finalCount)
+	assert.Len(t, renderer.changes, 2)
+	assert.Equal(t, messages.MessageStatusStarted, renderer.changes[0].Status)
+	assert.Equal(t, messages.MessageStatusSuccess, renderer.changes[1].Status)
+}
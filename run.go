@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+func (p *Progress) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// Run pushes a started message to the progress log, executes fn, and finishes the message based on the returned error: with success status if fn returns nil and with error status and the error as details otherwise. The context passed to fn is derived from the one given to StartContext and it is cancelled with ErrTimedOut as cause, if the message times out. If fn returns nil after the message has timed out, ErrTimedOut is returned.
+//
+// If fn panics, the message is finished with error status and the stack trace as details. The panic is re-raised after the message has been rendered.
+func Run(p *Progress, key, message string, fn func(ctx context.Context) error) error {
+	return p.Task(key, message).Run(fn)
+}
+
+// Run starts the task, executes fn, and finishes the task based on the returned error. See Run function for details.
+func (t *Task) Run(fn func(ctx context.Context) error) error {
+	if err := t.Start(); err != nil {
+		return err
+	}
+
+	return t.run(t.progress.context(), fn)
+}
+
+func (t *Task) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Cancel the context passed to fn, if the task times out.
+	timedOut := t.TimedOut()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		select {
+		case <-timedOut:
+			cancel(ErrTimedOut)
+		case <-ctx.Done():
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = t.push(messages.Update{
+				Status:  messages.MessageStatusError,
+				Details: fmt.Sprintf("panic: %v\n\n%s", r, strings.TrimSpace(string(debug.Stack()))),
+				Err:     fmt.Errorf("panic: %v", r),
+			})
+			t.progress.WaitForRender()
+			panic(r)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		_ = t.Fail(err)
+		return err
+	}
+
+	if err := t.Succeed(); err != nil {
+		// If the task timed out, its message has already been finished with the timeout status.
+		if isClosed(timedOut) {
+			return ErrTimedOut
+		}
+		return err
+	}
+	return nil
+}
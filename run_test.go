@@ -0,0 +1,128 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey string
+
+func TestRun_Output(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	ctx := context.WithValue(context.Background(), contextKey("test"), "value")
+	taskLog := progress.NewProgress(cfg)
+	taskLog.StartContext(ctx)
+
+	err := progress.Run(taskLog, "success", "Test success", func(ctx context.Context) error {
+		assert.Equal(t, "value", ctx.Value(contextKey("test")))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	testErr := errors.New("test error")
+	err = progress.Run(taskLog, "error", "Test error", func(_ context.Context) error {
+		return testErr
+	})
+	assert.ErrorIs(t, err, testErr)
+
+	taskLog.Stop()
+
+	output := buf.String()
+
+	expected := removeColorsOnWindows("\x1b[32m✓ \x1b[0mTest success                                                                                      \n\x1b[31m✗ \x1b[0mTest error                                                                                        \n  \x1b[90mtest error\x1b[0m\n")
+	assert.Equal(t, expected, output)
+}
+
+func TestRun_Panic(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+	defer taskLog.Stop()
+
+	assert.PanicsWithValue(t, "test panic", func() {
+		_ = progress.Run(taskLog, "panic", "Test panic", func(_ context.Context) error {
+			panic("test panic")
+		})
+	})
+
+	// The panicking message should be rendered before the panic is re-raised.
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output, "✗ Test panic"), "output should start with the failed message")
+	assert.Contains(t, output, "\n  panic: test panic\n")
+	assert.Contains(t, output, "runtime/debug.Stack()")
+}
+
+func TestRun_ErrorsIfCalledBeforeStart(t *testing.T) {
+	t.Parallel()
+	taskLog := progress.NewProgress(nil)
+	called := false
+	err := progress.Run(taskLog, "", "Test run", func(_ context.Context) error {
+		called = true
+		return nil
+	})
+	assert.EqualError(t, err, "can not push updates into progress log that has not been started")
+	assert.False(t, called)
+}
+
+func TestTask_Run_Timeout(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	task := taskLog.Task("timeout", "Test timeout")
+	assert.NoError(t, task.SetTimeout(time.Millisecond*50))
+	err := task.Run(func(ctx context.Context) error {
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+	assert.ErrorIs(t, err, progress.ErrTimedOut)
+
+	// Finish returns the error of the timed out message.
+	err = taskLog.Finish()
+	assert.ErrorIs(t, err, progress.ErrTimedOut)
+	assert.EqualError(t, err, "Test timeout: Timed out after 0.05 s")
+
+	output := buf.String()
+	assert.Equal(t, 1, strings.Count(output, "✗ Test timeout"))
+	assert.Contains(t, output, "\n  Timed out after 0.05 s\n")
+}
+
+func TestTask_Run_TimeoutIgnored(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+	defer taskLog.Stop()
+
+	// Function that ignores the context and succeeds after the timeout still fails the run.
+	task := taskLog.Task("timeout", "Test timeout")
+	assert.NoError(t, task.SetTimeout(time.Millisecond*50))
+	err := task.Run(func(context.Context) error {
+		<-task.TimedOut()
+		return nil
+	})
+	assert.ErrorIs(t, err, progress.ErrTimedOut)
+}
@@ -0,0 +1,159 @@
+package progress
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+const (
+	// SlogKeyAttr is the name of the slog attribute that routes a record into the progress message with the attribute's value as key.
+	SlogKeyAttr = "progress.key"
+	// SlogStatusAttr is the name of the slog attribute that overrides the status derived from the level of a record routed into a progress message.
+	SlogStatusAttr = "progress.status"
+)
+
+// SlogHandlerOptions are options for SlogHandler.
+type SlogHandlerOptions struct {
+	// Level defines the minimum level of records to handle. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+}
+
+// SlogHandler is a slog.Handler that outputs records above the in-progress messages of a progress log. Records with SlogKeyAttr attribute are pushed to the progress log as updates instead.
+type SlogHandler struct {
+	progress *Progress
+	level    slog.Leveler
+	attrs    []string
+	prefix   string
+	key      string
+	status   messages.MessageStatus
+}
+
+// NewSlogHandler returns a slog.Handler that writes records to the progress log p. Use nil opts for default options.
+//
+// Records are outputted as lines above the in-progress messages with the level colored with the status color that matches the level. Records with SlogKeyAttr attribute are pushed as updates to the message identified by the attribute value: the record's message is used as message, other attributes as details, and status is determined from the level (error → error, warning → warning, info → success, debug → started) unless overridden with SlogStatusAttr attribute.
+func NewSlogHandler(p *Progress, opts *SlogHandlerOptions) *SlogHandler {
+	h := &SlogHandler{
+		progress: p,
+		level:    slog.LevelInfo,
+	}
+	if opts != nil && opts.Level != nil {
+		h.level = opts.Level
+	}
+	return h
+}
+
+func getStatusFromLevel(level slog.Level) messages.MessageStatus {
+	switch {
+	case level >= slog.LevelError:
+		return messages.MessageStatusError
+	case level >= slog.LevelWarn:
+		return messages.MessageStatusWarning
+	case level >= slog.LevelInfo:
+		return messages.MessageStatusSuccess
+	default:
+		return messages.MessageStatusStarted
+	}
+}
+
+// Enabled reports whether the handler handles records at given level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle outputs the record above the in-progress messages or, if the record has SlogKeyAttr attribute, pushes it to the progress log as an update.
+func (h *SlogHandler) Handle(_ context.Context, record slog.Record) error {
+	clone := h.clone()
+	record.Attrs(func(attr slog.Attr) bool {
+		clone.addAttr(clone.prefix, attr)
+		return true
+	})
+
+	details := strings.Join(clone.attrs, " ")
+	if clone.key != "" {
+		status := clone.status
+		if status == "" {
+			status = getStatusFromLevel(record.Level)
+		}
+		return h.progress.Push(messages.Update{
+			Key:     clone.key,
+			Message: record.Message,
+			Status:  status,
+			Details: details,
+		})
+	}
+
+	cfg := h.progress.config
+	if cfg.OutputFormat == messages.OutputFormatJSON {
+		// Output lines are encoded as JSON strings, so escape sequences would only make them harder to consume.
+		cfg.DisableColors = true
+		cfg.ForceColors = false
+	}
+	line := cfg.GetStatusColor(getStatusFromLevel(record.Level)).Sprint(record.Level.String()) + " " + record.Message
+	if details != "" {
+		line += " " + cfg.GetDetailsColor().Sprint(details)
+	}
+	_, err := h.progress.Writer().Write([]byte(line + "\n"))
+	return err
+}
+
+// WithAttrs returns a new handler that includes given attributes in every handled record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := h.clone()
+	for _, attr := range attrs {
+		clone.addAttr(clone.prefix, attr)
+	}
+	return clone
+}
+
+// WithGroup returns a new handler that qualifies the keys of the attributes of handled records with group name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.prefix += name + "."
+	return clone
+}
+
+func (h *SlogHandler) clone() *SlogHandler {
+	clone := *h
+	clone.attrs = append([]string(nil), h.attrs...)
+	return &clone
+}
+
+func (h *SlogHandler) addAttr(prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			h.addAttr(prefix, groupAttr)
+		}
+		return
+	}
+
+	key := prefix + attr.Key
+	value := attr.Value.String()
+	switch key {
+	case SlogKeyAttr:
+		h.key = value
+		return
+	case SlogStatusAttr:
+		h.status = messages.MessageStatus(value)
+		return
+	}
+
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	h.attrs = append(h.attrs, key+"="+value)
+}
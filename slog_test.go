@@ -0,0 +1,50 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	logger := slog.New(progress.NewSlogHandler(taskLog, nil))
+	logger.Debug("Filtered debug")
+	logger.Info("Test info", "count", 3, slog.Group("request", "id", "abc 123"))
+	logger.With(progress.SlogKeyAttr, "server", progress.SlogStatusAttr, "started").WithGroup("server").Info("Creating server", "name", "test")
+	logger.Warn("Test warning")
+	logger.Error("Server failed", progress.SlogKeyAttr, "server", "error", "not enough resources")
+	logger.Info("Test skipped", progress.SlogKeyAttr, "skipped", progress.SlogStatusAttr, "skipped")
+
+	taskLog.Stop()
+
+	assert.Equal(t, `INFO Test info count=3 request.id="abc 123"
+WARN Test warning
+✗ Server failed`+strings.Repeat(" ", 85)+`
+  error="not enough resources"
+- Test skipped`+strings.Repeat(" ", 86)+"\n", buf.String())
+}
+
+func TestSlogHandler_ErrorsIfNotStarted(t *testing.T) {
+	t.Parallel()
+	taskLog := progress.NewProgress(nil)
+
+	handler := progress.NewSlogHandler(taskLog, &progress.SlogHandlerOptions{Level: slog.LevelDebug})
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelDebug))
+	err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelDebug, "test", 0))
+	assert.EqualError(t, err, "can not write into progress log that has not been started")
+}
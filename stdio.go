@@ -0,0 +1,145 @@
+package progress
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+)
+
+type capturedFile struct {
+	// file is the value of os.Stdout or os.Stderr variable before capturing.
+	file *os.File
+	// original writes into the original destination of file also after file has been redirected.
+	original *os.File
+	writer   *os.File
+}
+
+// stdioCapture is allocated when Progress is created to share it with copies of Progress, e.g., when Stop is deferred before CaptureStdio is called.
+type stdioCapture struct {
+	captured bool
+	stdout   capturedFile
+	stderr   capturedFile
+	wg       sync.WaitGroup
+}
+
+// retarget is a request to switch the target of the renderer from a file into another file that refers to the same destination.
+type retarget struct {
+	from *os.File
+	to   *os.File
+}
+
+// CaptureStdio outputs lines written to the standard output and error of the process above the in-progress messages until Stop is called. Returns error if called before Start, after Stop, or more than once.
+func (p *Progress) CaptureStdio() error {
+	if p.stopChan == nil {
+		return fmt.Errorf("can not capture stdio of progress log that has not been started")
+	}
+	if isClosed(p.stoppedChan) {
+		return fmt.Errorf("can not capture stdio of progress log that has been stopped")
+	}
+	capture := p.stdio
+	if capture.captured {
+		return fmt.Errorf("can not capture stdio of progress log more than once")
+	}
+
+	var err error
+	if capture.stdout, err = p.captureFile(&capture.wg, &os.Stdout); err != nil {
+		return err
+	}
+	if capture.stderr, err = p.captureFile(&capture.wg, &os.Stderr); err != nil {
+		p.releaseFile(&os.Stdout, capture.stdout)
+		return err
+	}
+	capture.captured = true
+	return nil
+}
+
+func (p *Progress) captureFile(wg *sync.WaitGroup, file **os.File) (capturedFile, error) {
+	original, err := duplicateFile(*file)
+	if err != nil {
+		return capturedFile{}, err
+	}
+	captured := capturedFile{file: *file, original: original}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		closeDuplicate(captured)
+		return capturedFile{}, fmt.Errorf("failed to create pipe for capturing %s: %w", original.Name(), err)
+	}
+	captured.writer = w
+
+	// Switch the renderer to the original destination before redirecting to avoid capturing the rendered messages.
+	p.retarget(captured.file, original)
+	if err := redirectFile(file, w); err != nil {
+		p.retarget(original, captured.file)
+		closeDuplicate(captured)
+		_ = r.Close()
+		_ = w.Close()
+		return capturedFile{}, err
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer r.Close()
+
+		// Read complete lines to avoid mixing incomplete lines of stdout and stderr.
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				if line[len(line)-1] != '\n' {
+					line += "\n"
+				}
+				if _, writeErr := p.Writer().Write([]byte(line)); writeErr != nil {
+					_, _ = original.WriteString(line)
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return captured, nil
+}
+
+// releaseFile restores the captured file and switches the renderer back to it.
+func (p *Progress) releaseFile(file **os.File, captured capturedFile) {
+	_ = restoreFile(file, captured)
+	_ = captured.writer.Close()
+	p.retarget(captured.original, captured.file)
+	closeDuplicate(captured)
+}
+
+// retarget switches the target of the renderer in the goroutine started by Start, if the renderer writes into from.
+func (p *Progress) retarget(from, to *os.File) {
+	if from == to {
+		return
+	}
+	p.retargetChan <- retarget{from: from, to: to}
+	<-p.errorChan
+}
+
+func (p *Progress) handleRetarget(r retarget) {
+	switcher, ok := p.renderer.(TargetSwitcher)
+	if !ok {
+		return
+	}
+	if target, ok := switcher.Target().(*os.File); ok && target.Fd() == r.from.Fd() {
+		switcher.SetTarget(r.to)
+	}
+}
+
+// restoreStdio restores the original files, if stdio has been captured, and waits until the captured output has been handled.
+func (p *Progress) restoreStdio() {
+	capture := p.stdio
+	if !capture.captured {
+		return
+	}
+	capture.captured = false
+
+	p.releaseFile(&os.Stdout, capture.stdout)
+	p.releaseFile(&os.Stderr, capture.stderr)
+	capture.wg.Wait()
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+package progress
+
+import "os"
+
+// duplicateFile returns file as is, as only the variable pointing to the file is replaced when redirecting.
+func duplicateFile(file *os.File) (*os.File, error) {
+	return file, nil
+}
+
+func closeDuplicate(_ capturedFile) {}
+
+// redirectFile replaces the variable pointed by file with w.
+func redirectFile(file **os.File, w *os.File) error {
+	*file = w
+	return nil
+}
+
+// restoreFile restores the variable pointed by file.
+func restoreFile(file **os.File, captured capturedFile) error {
+	*file = captured.file
+	return nil
+}
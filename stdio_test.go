@@ -0,0 +1,58 @@
+package progress_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests in this file modify os.Stdout and os.Stderr and, thus, must not be run in parallel.
+
+func TestProgress_CaptureStdio(t *testing.T) { //nolint:paralleltest // Test modifies os.Stdout and os.Stderr.
+	stdout, stderr := os.Stdout, os.Stderr
+
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	taskLog := progress.NewProgress(cfg)
+	err := taskLog.CaptureStdio()
+	assert.EqualError(t, err, "can not capture stdio of progress log that has not been started")
+
+	taskLog.Start()
+	assert.NoError(t, taskLog.CaptureStdio())
+	assert.EqualError(t, taskLog.CaptureStdio(), "can not capture stdio of progress log more than once")
+
+	fmt.Fprintln(os.Stdout, "Test stdout")
+	fmt.Fprint(os.Stderr, "Test stderr")
+
+	taskLog.Stop()
+
+	assert.Equal(t, stdout, os.Stdout)
+	assert.Equal(t, stderr, os.Stderr)
+	assert.Contains(t, buf.String(), "Test stdout\n")
+	assert.Contains(t, buf.String(), "Test stderr\n")
+}
+
+func TestProgress_CaptureStdio_RestoresOnPanic(t *testing.T) { //nolint:paralleltest // Test modifies os.Stdout and os.Stderr.
+	stdout, stderr := os.Stdout, os.Stderr
+
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	assert.Panics(t, func() {
+		taskLog := progress.NewProgress(cfg)
+		taskLog.Start()
+		defer taskLog.Stop()
+
+		assert.NoError(t, taskLog.CaptureStdio())
+		panic("test panic")
+	})
+
+	assert.Equal(t, stdout, os.Stdout)
+	assert.Equal(t, stderr, os.Stderr)
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package progress
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// duplicateFile returns a new file that refers to the same destination as file.
+func duplicateFile(file *os.File) (*os.File, error) {
+	fd, err := unix.Dup(int(file.Fd())) // #nosec G115 -- File-descriptor should be safe to convert into int.
+	if err != nil {
+		return nil, fmt.Errorf("failed to duplicate %s: %w", file.Name(), err)
+	}
+	unix.CloseOnExec(fd)
+	return os.NewFile(uintptr(fd), file.Name()), nil // #nosec G115 -- Duplicated file-descriptor is not negative.
+}
+
+func closeDuplicate(captured capturedFile) {
+	_ = captured.original.Close()
+}
+
+// redirectFile redirects the file descriptor of file into w. Writes into the file descriptor, e.g., through os.Stdout, log package, or by child processes, end up in w.
+func redirectFile(file **os.File, w *os.File) error {
+	// Fd puts w into blocking mode, which is then shared with the redirected file descriptor.
+	if err := unix.Dup2(int(w.Fd()), int((*file).Fd())); err != nil { // #nosec G115 -- File-descriptors should be safe to convert into int.
+		return fmt.Errorf("failed to redirect %s: %w", (*file).Name(), err)
+	}
+	return nil
+}
+
+// restoreFile restores the original destination of the redirected file descriptor.
+func restoreFile(_ **os.File, captured capturedFile) error {
+	if err := unix.Dup2(int(captured.original.Fd()), int(captured.file.Fd())); err != nil { // #nosec G115 -- File-descriptors should be safe to convert into int.
+		return fmt.Errorf("failed to restore %s: %w", captured.file.Name(), err)
+	}
+	return nil
+}
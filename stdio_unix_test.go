@@ -0,0 +1,38 @@
+//go:build unix
+
+package progress_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress_CaptureStdio_FileDescriptors(t *testing.T) { //nolint:paralleltest // Test modifies the file descriptors of stdout and stderr.
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+	assert.NoError(t, taskLog.CaptureStdio())
+
+	log.Print("Test log")
+	_, err := syscall.Write(1, []byte("Test write\n"))
+	assert.NoError(t, err)
+	cmd := exec.Command("sh", "-c", "echo Test child")
+	cmd.Stdout = os.Stdout
+	assert.NoError(t, cmd.Run())
+
+	taskLog.Stop()
+
+	assert.Contains(t, buf.String(), "Test log\n")
+	assert.Contains(t, buf.String(), "Test write\n")
+	assert.Contains(t, buf.String(), "Test child\n")
+}
@@ -0,0 +1,146 @@
+package progress
+
+import (
+	"sync"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// subscription forwards events from the message store to a channel. Events are queued without limit to avoid blocking the goroutine handling progress logging when the receiver is slow.
+type subscription struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []messages.Event
+	closed bool
+	events chan messages.Event
+	done   chan bool
+	once   sync.Once
+	// unsubscribeStore removes the subscription from the message store. Set when the subscription is added to the store.
+	unsubscribeStore func()
+}
+
+func newSubscription() *subscription {
+	s := &subscription{
+		events: make(chan messages.Event),
+		done:   make(chan bool),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	go s.forward()
+	return s
+}
+
+func (s *subscription) push(event messages.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.queue = append(s.queue, event)
+		s.cond.Signal()
+	}
+}
+
+// close closes the channel after the queued events have been received.
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.cond.Signal()
+}
+
+// unsubscribe discards the queued events and closes the channel.
+func (s *subscription) unsubscribe() {
+	s.once.Do(func() {
+		close(s.done)
+		s.close()
+	})
+}
+
+func (s *subscription) forward() {
+	defer close(s.events)
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		event := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.events <- event:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// removeSubscription removes s from the message store and from subscriptions.
+func removeSubscription(subscriptions []*subscription, s *subscription) []*subscription {
+	for i, existing := range subscriptions {
+		if existing == s {
+			s.unsubscribeStore()
+			return append(subscriptions[:i:i], subscriptions[i+1:]...)
+		}
+	}
+	return subscriptions
+}
+
+func closeSubscriptions(subscriptions []*subscription) {
+	for _, s := range subscriptions {
+		s.close()
+	}
+}
+
+// Subscribe returns a channel that receives an event for each change in the state of messages, e.g., when a message is created, started, or finished, and a function for unsubscribing. The events are queued, so slow receivers do not block the progress log. The channel is closed after the remaining events have been received when the progress log is stopped, or immediately when unsubscribed. If called after Stop, returns a closed channel.
+func (p *Progress) Subscribe() (<-chan messages.Event, func()) {
+	s := newSubscription()
+
+	select {
+	case <-p.stoppedChan:
+		s.close()
+		return s.events, s.unsubscribe
+	default:
+	}
+
+	if p.subscribeChan == nil {
+		// Not started yet, so the store can be accessed directly. The goroutine handling progress logging takes over the subscriptions when started.
+		p.subscriptions = append(p.subscriptions, s)
+		s.unsubscribeStore = p.store.Subscribe(s.push)
+		return s.events, func() { p.unsubscribe(s) }
+	}
+
+	select {
+	case p.subscribeChan <- s:
+	case <-p.stoppedChan:
+		s.close()
+	}
+	return s.events, func() { p.unsubscribe(s) }
+}
+
+// unsubscribe closes the subscription and removes it from the progress log, so that no more events are pushed into it.
+func (p *Progress) unsubscribe(s *subscription) {
+	s.unsubscribe()
+
+	select {
+	case <-p.stoppedChan:
+		// The subscriptions have already been closed.
+		return
+	default:
+	}
+
+	if p.unsubscribeChan == nil {
+		// Not started yet, so the store can be accessed directly.
+		p.subscriptions = removeSubscription(p.subscriptions, s)
+		return
+	}
+
+	select {
+	case p.unsubscribeChan <- s:
+	case <-p.stoppedChan:
+	}
+}
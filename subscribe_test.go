@@ -0,0 +1,50 @@
+package progress_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/UpCloudLtd/progress/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress_Subscribe(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	cfg.Target = bytes.NewBuffer(nil)
+
+	taskLog := progress.NewProgress(cfg)
+	beforeStart, _ := taskLog.Subscribe()
+	unsubscribedBeforeStart, unsubscribe := taskLog.Subscribe()
+	unsubscribe()
+	taskLog.Start()
+	afterStart, unsubscribeAfterStop := taskLog.Subscribe()
+	unsubscribed, unsubscribe := taskLog.Subscribe()
+	unsubscribe()
+	unsubscribe()
+
+	assert.NoError(t, taskLog.Push(messages.Update{Key: "test", Message: "Test message", Status: messages.MessageStatusStarted}))
+	assert.NoError(t, taskLog.Push(messages.Update{Key: "test", Status: messages.MessageStatusSuccess}))
+
+	taskLog.Stop()
+
+	for _, events := range []<-chan messages.Event{beforeStart, afterStart} {
+		var types []messages.EventType
+		for event := range events {
+			assert.Equal(t, "test", event.Message.Key)
+			types = append(types, event.Type)
+		}
+		assert.Equal(t, []messages.EventType{messages.EventCreated, messages.EventStarted, messages.EventFinished}, types)
+	}
+
+	for _, events := range []<-chan messages.Event{unsubscribedBeforeStart, unsubscribed} {
+		_, ok := <-events
+		assert.False(t, ok)
+	}
+	unsubscribeAfterStop()
+
+	afterStop, _ := taskLog.Subscribe()
+	_, ok := <-afterStop
+	assert.False(t, ok)
+}
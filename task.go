@@ -0,0 +1,156 @@
+package progress
+
+import (
+	"time"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// Task is a handle for pushing updates to a single progress message without having to repeat its key and message in every update.
+type Task struct {
+	progress        *Progress
+	key             string
+	parentKey       string
+	message         string
+	progressMessage string
+	timeout         time.Duration
+	created         bool
+}
+
+// Task returns a handle for the progress message identified by key. If key is empty, message is used as key. The message is created when the first status update, e.g. Start, is pushed.
+func (p *Progress) Task(key, message string) *Task {
+	if key == "" {
+		key = message
+	}
+
+	return &Task{
+		progress: p,
+		key:      key,
+		message:  message,
+	}
+}
+
+// Subtask returns a handle for a progress message that is rendered as a child of the task's message. If key is empty, message is used as key.
+func (t *Task) Subtask(key, message string) *Task {
+	subtask := t.progress.Task(key, message)
+	subtask.parentKey = t.key
+	return subtask
+}
+
+// Key returns the key used to identify the message of the task.
+func (t *Task) Key() string {
+	return t.key
+}
+
+func (t *Task) push(update messages.Update) error {
+	update.Key = t.key
+	update.ParentKey = t.parentKey
+
+	// Include message only when creating the message to avoid re-creating the message if it has already been finished, e.g., by a timeout.
+	if !t.created {
+		update.Message = t.message
+		if update.Timeout == 0 {
+			update.Timeout = t.timeout
+		}
+	}
+
+	// Keep the progress message visible until the task is finished, as updates without progress message clear it.
+	if !update.Status.IsFinished() {
+		update.ProgressMessage = t.progressMessage
+	}
+
+	if err := t.progress.Push(update); err != nil {
+		return err
+	}
+	t.created = true
+	return nil
+}
+
+// Pending sets the status of the task to pending.
+func (t *Task) Pending() error {
+	return t.push(messages.Update{Status: messages.MessageStatusPending})
+}
+
+// Start sets the status of the task to started.
+func (t *Task) Start() error {
+	return t.push(messages.Update{Status: messages.MessageStatusStarted})
+}
+
+// SetMessage updates the text of the task's message.
+func (t *Task) SetMessage(message string) error {
+	t.message = message
+	return t.push(messages.Update{Message: message})
+}
+
+// SetProgress updates the progress indicator text appended to the message in TTY terminals, e.g. `(50 %)`.
+func (t *Task) SetProgress(progressMessage string) error {
+	t.progressMessage = progressMessage
+	return t.push(messages.Update{})
+}
+
+// SetCurrent updates the numeric progress of the task. In TTY terminals, numeric progress is rendered, for example, as a progress bar.
+func (t *Task) SetCurrent(current, total int64) error {
+	return t.push(messages.Update{Current: current, Total: total})
+}
+
+// SetMaxAttempts sets the maximum number of attempts rendered with the attempt counter of the task.
+func (t *Task) SetMaxAttempts(maxAttempts int) error {
+	return t.push(messages.Update{MaxAttempts: maxAttempts})
+}
+
+// FailAttempt records that the current attempt of the task failed with err and moves the task to the next attempt. Errors of earlier attempts are listed in the details of the finished message.
+func (t *Task) FailAttempt(err error) error {
+	failure := "unknown error"
+	if err != nil {
+		failure = err.Error()
+	}
+	return t.push(messages.Update{FailedAttempt: failure})
+}
+
+// SetTimeout sets the maximum duration the task can be in started state. When the task times out, it is finished with error status. Use TimedOut to get notified when the task times out. If the message of the task has not been created yet, the timeout is applied when the message is created.
+func (t *Task) SetTimeout(timeout time.Duration) error {
+	t.timeout = timeout
+	if !t.created {
+		return nil
+	}
+	return t.push(messages.Update{Timeout: timeout})
+}
+
+// AppendLog appends line to the log of the task. See Progress.AppendLog for details.
+func (t *Task) AppendLog(line string) error {
+	return t.push(messages.Update{Log: line})
+}
+
+// SetDetails updates the details outputted under the message when the task is finished.
+func (t *Task) SetDetails(details string) error {
+	return t.push(messages.Update{Details: details})
+}
+
+// Succeed sets the status of the task to success.
+func (t *Task) Succeed() error {
+	return t.push(messages.Update{Status: messages.MessageStatusSuccess})
+}
+
+// Warn sets the status of the task to warning. If details is not empty, it is outputted under the message.
+func (t *Task) Warn(details string) error {
+	return t.push(messages.Update{Status: messages.MessageStatusWarning, Details: details})
+}
+
+// Fail sets the status of the task to error. If err is not nil, its message is outputted under the message.
+func (t *Task) Fail(err error) error {
+	details := ""
+	if err != nil {
+		details = err.Error()
+	}
+	return t.push(messages.Update{Status: messages.MessageStatusError, Details: details, Err: err})
+}
+
+// Finish sets the status of the task based on statuses of its subtasks: error, if any of the subtasks failed, was cancelled, or has unknown status, warning, if any of the subtasks has warning status, and success otherwise. Errors, if any of the subtasks has not finished yet.
+func (t *Task) Finish() error {
+	return t.push(messages.Update{StatusFromChildren: true})
+}
+
+// Skip sets the status of the task to skipped.
+func (t *Task) Skip() error {
+	return t.push(messages.Update{Status: messages.MessageStatusSkipped})
+}
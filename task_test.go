@@ -0,0 +1,86 @@
+package progress_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTask_ValidationErrors(t *testing.T) {
+	t.Parallel()
+	taskLog := progress.NewProgress(nil)
+	task := taskLog.Task("test", "Test task")
+
+	err := task.Start()
+	assert.EqualError(t, err, "can not push updates into progress log that has not been started")
+
+	taskLog.Start()
+	defer taskLog.Stop()
+
+	err = task.SetProgress("(50 %)")
+	assert.EqualError(t, err, `can not push message with invalid status ""`)
+
+	err = taskLog.Task("", "").Start()
+	assert.EqualError(t, err, "can not push message without key or message")
+}
+
+func TestTask_Output(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	success := taskLog.Task("", "Test success")
+	assert.Equal(t, "Test success", success.Key())
+	assert.NoError(t, success.Start())
+	assert.NoError(t, success.SetProgress("(50 %)"))
+	assert.NoError(t, success.Succeed())
+
+	warning := taskLog.Task("warning", "Test warning")
+	assert.NoError(t, warning.Start())
+	assert.NoError(t, warning.Warn("Test warning details"))
+
+	failure := taskLog.Task("error", "Test error")
+	assert.NoError(t, failure.Start())
+	assert.NoError(t, failure.SetDetails("Overwritten details"))
+	assert.NoError(t, failure.Fail(errors.New("test error")))
+
+	skipped := taskLog.Task("skipped", "Test skipped")
+	assert.NoError(t, skipped.Skip())
+
+	taskLog.Stop()
+
+	output := buf.String()
+
+	expected := removeColorsOnWindows("\x1b[32m✓ \x1b[0mTest success                                                                                      \n\x1b[33m! \x1b[0mTest warning                                                                                      \n  \x1b[90mTest warning details\x1b[0m\n\x1b[31m✗ \x1b[0mTest error                                                                                        \n  \x1b[90mtest error\x1b[0m\n\x1b[35m- \x1b[0mTest skipped                                                                                      \n")
+	assert.Equal(t, expected, output)
+}
+
+func TestTask_Subtask_Finish(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	parent := taskLog.Task("parent", "Test parent")
+	assert.NoError(t, parent.Start())
+	child := parent.Subtask("child", "Test child")
+	assert.NoError(t, child.Start())
+	assert.NoError(t, child.Fail(errors.New("test error")))
+	assert.NoError(t, parent.Finish())
+
+	taskLog.Stop()
+
+	output := buf.String()
+	assert.Contains(t, output, "✗ Test parent                                                                                       \n  ✗ Test child                                                                                      \n    test error\n")
+}
@@ -0,0 +1,70 @@
+package progress
+
+import (
+	"time"
+
+	"github.com/UpCloudLtd/progress/messages"
+)
+
+// ErrTimedOut is the cause of the context passed to functions executed with Run or Group when their message times out. It is also the error of the timed out message, e.g., in the errors returned by Finish.
+var ErrTimedOut = messages.ErrTimedOut
+
+type timeoutWaiter struct {
+	key    string
+	waiter chan bool
+}
+
+func (p *Progress) addTimeoutWaiter(waiter timeoutWaiter) {
+	if msg := p.store.GetMessage(waiter.key); msg != nil && msg.Status.IsFinished() {
+		if msg.TimedOut {
+			close(waiter.waiter)
+		}
+		return
+	}
+	p.timeoutWaiters[waiter.key] = append(p.timeoutWaiters[waiter.key], waiter.waiter)
+}
+
+// removeTimeoutWaiters removes waiters of the message targeted by the update, if the message has been finished.
+func (p *Progress) removeTimeoutWaiters(update messages.Update) {
+	key := update.Key
+	if key == "" {
+		key = update.Message
+	}
+	if _, ok := p.timeoutWaiters[key]; !ok {
+		return
+	}
+
+	if msg := p.store.GetMessage(key); msg == nil || msg.Status.IsFinished() {
+		delete(p.timeoutWaiters, key)
+	}
+}
+
+func (p *Progress) expireOverdue() {
+	for _, msg := range p.store.ExpireOverdue(time.Now()) {
+		p.recorder.record(recordedEvent{Expired: recordChange(msg)})
+		for _, waiter := range p.timeoutWaiters[msg.Key] {
+			close(waiter)
+		}
+		delete(p.timeoutWaiters, msg.Key)
+	}
+}
+
+// TimedOut returns a channel that is closed when the task times out. See SetTimeout.
+func (t *Task) TimedOut() <-chan bool {
+	waiter := make(chan bool)
+	if t.progress.timeoutWaitChan == nil {
+		return waiter
+	}
+
+	t.progress.timeoutWaitChan <- timeoutWaiter{key: t.key, waiter: waiter}
+	return waiter
+}
+
+func isClosed(ch <-chan bool) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
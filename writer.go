@@ -0,0 +1,28 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+)
+
+type outputWriter struct {
+	progress *Progress
+}
+
+// Writer returns an io.Writer for outputting arbitrary text while the progress log is running. Complete lines written to the Writer are outputted above the in-progress messages when the messages are next rendered. Incomplete last line is outputted when it is completed or when the progress log is stopped. Writes error if done before Start or after the context given to StartContext has been cancelled and panic if done after Stop.
+func (p *Progress) Writer() io.Writer {
+	return outputWriter{progress: p}
+}
+
+func (w outputWriter) Write(b []byte) (int, error) {
+	p := w.progress
+	if p.outputChan == nil {
+		return 0, fmt.Errorf("can not write into progress log that has not been started")
+	}
+
+	p.outputChan <- string(b)
+	if err := <-p.errorChan; err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,44 @@
+package progress_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/UpCloudLtd/progress"
+	"github.com/UpCloudLtd/progress/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress_Writer(t *testing.T) {
+	t.Parallel()
+	cfg := progress.GetDefaultOutputConfig()
+	buf := bytes.NewBuffer(nil)
+	cfg.Target = buf
+	cfg.DisableColors = true
+
+	taskLog := progress.NewProgress(cfg)
+	taskLog.Start()
+
+	w := taskLog.Writer()
+	_, err := fmt.Fprint(w, "first line\nsecond ")
+	assert.NoError(t, err)
+	_, err = fmt.Fprintln(w, "line")
+	assert.NoError(t, err)
+	assert.NoError(t, taskLog.Push(messages.Update{Message: "Test success", Status: messages.MessageStatusSuccess}))
+	_, err = fmt.Fprint(w, "incomplete line")
+	assert.NoError(t, err)
+
+	taskLog.Stop()
+
+	assert.Equal(t, "first line\nsecond line\nincomplete line\n✓ Test success"+strings.Repeat(" ", 86)+"\n", buf.String())
+}
+
+func TestProgress_Writer_ErrorsIfNotStarted(t *testing.T) {
+	t.Parallel()
+	taskLog := progress.NewProgress(nil)
+
+	_, err := fmt.Fprintln(taskLog.Writer(), "test")
+	assert.EqualError(t, err, "can not write into progress log that has not been started")
+}